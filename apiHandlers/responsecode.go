@@ -8,6 +8,7 @@ const (
 	ErrMissingRequiredField                   = "GEN_00003"
 	ErrMissingRequiredParam                   = "GEN_00004"
 	ErrInvalidScope                           = "GEN_00005"
+	ErrInvalidParam                           = "GEN_00006"
 	ErrInternalServerError                    = "GEN_99999"
 	ErrInvalidPostalCode                      = "USR_00001"
 	ErrUserProfileNotFound                    = "USR_00002"
@@ -15,6 +16,29 @@ const (
 	ErrShoplistNotOwned                       = "SHP_00002"
 	ErrShoplistItemNotFound                   = "SHP_00003"
 	ErrMissingRequiredFieldUpdateShoplistItem = "SHP_00004"
+	ErrInvalidShoplistItemUnit                = "SHP_00005"
+	ErrInvalidShoplistItemQuantity            = "SHP_00006"
+	ErrInvalidShoplistItemCategory            = "SHP_00007"
+	ErrInvalidShoplistItemOrder               = "SHP_00008"
+	ErrShoplistNewOwnerNotMember              = "SHP_00009"
+	ErrShoplistMemberNotFound                 = "SHP_00010"
+	ErrShoplistCannotRemoveSelf               = "SHP_00011"
+	ErrShoplistMemberBanned                   = "SHP_00012"
+	ErrShoplistMemberReadOnly                 = "SHP_00013"
+	ErrInvalidShoplistMemberRole              = "SHP_00014"
+	ErrShoplistCannotChangeOwnerRole          = "SHP_00015"
+	ErrInvalidShareCodeExpiry                 = "SHP_00016"
+	ErrInvalidShareCodeMaxUses                = "SHP_00017"
+	ErrShareCodeExhausted                     = "SHP_00018"
+	ErrShoplistInviteeNotFound                = "SHP_00019"
+	ErrShoplistAlreadyMember                  = "SHP_00020"
+	ErrShoplistInvitationNotFound             = "SHP_00021"
+	ErrInvalidShareCode                       = "SHP_00022"
+	ErrTooManyJoinAttempts                    = "SHP_00023"
+	ErrShoplistVersionConflict                = "SHP_00024"
+	ErrTooManyOfflineOps                      = "SHP_00025"
+	ErrTooManyBulkItems                       = "SHP_00026"
+	ErrInvalidShoplistItemRecurrence          = "SHP_00027"
 )
 
 var responseMap = map[string]response{
@@ -24,10 +48,34 @@ var responseMap = map[string]response{
 	ErrMissingRequiredField:                   {ErrMissingRequiredField, http.StatusBadRequest, "Missing field in body: %s"},
 	ErrMissingRequiredParam:                   {ErrMissingRequiredParam, http.StatusBadRequest, "Missing parameter: %s"},
 	ErrInvalidScope:                           {ErrInvalidScope, http.StatusForbidden, "Missing scope: %s"},
+	ErrInvalidParam:                           {ErrInvalidParam, http.StatusBadRequest, "Invalid parameter: %s"},
 	ErrInvalidPostalCode:                      {ErrInvalidPostalCode, http.StatusBadRequest, "Invalid postal code."},
 	ErrUserProfileNotFound:                    {ErrUserProfileNotFound, http.StatusNotFound, "User profile not found."},
 	ErrShoplistNotFound:                       {ErrShoplistNotFound, http.StatusNotFound, "Shoplist not found."},
 	ErrShoplistNotOwned:                       {ErrShoplistNotOwned, http.StatusForbidden, "Only the owner can perform this action."},
 	ErrShoplistItemNotFound:                   {ErrShoplistItemNotFound, http.StatusNotFound, "Item not found."},
-	ErrMissingRequiredFieldUpdateShoplistItem: {ErrMissingRequiredFieldUpdateShoplistItem, http.StatusBadRequest, "Request body must include at least one of item_name, brand_name, extra_info or Is_bought."},
+	ErrMissingRequiredFieldUpdateShoplistItem: {ErrMissingRequiredFieldUpdateShoplistItem, http.StatusBadRequest, "Request body must include at least one of item_name, brand_name, extra_info, quantity, unit, category or is_bought."},
+	ErrInvalidShoplistItemUnit:                {ErrInvalidShoplistItemUnit, http.StatusBadRequest, "Unit must be one of g, kg, ml, L, each or pack."},
+	ErrInvalidShoplistItemQuantity:            {ErrInvalidShoplistItemQuantity, http.StatusBadRequest, "Quantity must not be negative."},
+	ErrInvalidShoplistItemCategory:            {ErrInvalidShoplistItemCategory, http.StatusBadRequest, "Category is not a known category."},
+	ErrInvalidShoplistItemOrder:               {ErrInvalidShoplistItemOrder, http.StatusBadRequest, "Item IDs must not be empty and must list each item of the shoplist at most once."},
+	ErrShoplistNewOwnerNotMember:              {ErrShoplistNewOwnerNotMember, http.StatusBadRequest, "New owner must be another member of the shoplist."},
+	ErrShoplistMemberNotFound:                 {ErrShoplistMemberNotFound, http.StatusNotFound, "Member not found."},
+	ErrShoplistCannotRemoveSelf:               {ErrShoplistCannotRemoveSelf, http.StatusBadRequest, "Owner cannot remove themselves, leave the shoplist instead."},
+	ErrShoplistMemberBanned:                   {ErrShoplistMemberBanned, http.StatusForbidden, "User is not allowed to join this shoplist."},
+	ErrShoplistMemberReadOnly:                 {ErrShoplistMemberReadOnly, http.StatusForbidden, "Viewers cannot modify items."},
+	ErrInvalidShoplistMemberRole:              {ErrInvalidShoplistMemberRole, http.StatusBadRequest, "Role must be editor or viewer."},
+	ErrShoplistCannotChangeOwnerRole:          {ErrShoplistCannotChangeOwnerRole, http.StatusBadRequest, "Owner role can only change through an ownership transfer."},
+	ErrInvalidShareCodeExpiry:                 {ErrInvalidShareCodeExpiry, http.StatusBadRequest, "Share code expiry must be between 1 minute and 30 days."},
+	ErrInvalidShareCodeMaxUses:                {ErrInvalidShareCodeMaxUses, http.StatusBadRequest, "Max uses must not be negative."},
+	ErrShareCodeExhausted:                     {ErrShareCodeExhausted, http.StatusBadRequest, "Share code has reached its maximum number of uses."},
+	ErrShoplistInviteeNotFound:                {ErrShoplistInviteeNotFound, http.StatusNotFound, "Invitee not found."},
+	ErrShoplistAlreadyMember:                  {ErrShoplistAlreadyMember, http.StatusBadRequest, "User is already a member of the shoplist."},
+	ErrShoplistInvitationNotFound:             {ErrShoplistInvitationNotFound, http.StatusNotFound, "Invitation not found."},
+	ErrInvalidShareCode:                       {ErrInvalidShareCode, http.StatusBadRequest, "Invalid or expired share code."},
+	ErrTooManyJoinAttempts:                    {ErrTooManyJoinAttempts, http.StatusTooManyRequests, "Too many attempts, please try again later."},
+	ErrShoplistVersionConflict:                {ErrShoplistVersionConflict, http.StatusConflict, "The shoplist was changed by someone else, reload and try again."},
+	ErrTooManyOfflineOps:                      {ErrTooManyOfflineOps, http.StatusBadRequest, "A replay can have at most 200 operations."},
+	ErrTooManyBulkItems:                       {ErrTooManyBulkItems, http.StatusBadRequest, "A bulk request can have at most 100 items."},
+	ErrInvalidShoplistItemRecurrence:          {ErrInvalidShoplistItemRecurrence, http.StatusBadRequest, "Recurrence must be days:N with N from 1 to 365 or weekly:<weekday>."},
 }
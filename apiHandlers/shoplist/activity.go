@@ -0,0 +1,109 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// GetShoplistActivity returns the activity log of a shoplist
+// @Summary Get shoplist activity
+// @Description Returns who changed what in a shoplist, newest first. Each entry carries the actor, the time and the values before and after the change. Pass the returned next_cursor as cursor to get the next page.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param limit query int false "Number of entries per page, up to 100"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} ActivityPageResponse "Successfully got shoplist activity"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid limit or cursor"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/activity [get]
+func (h *ShoplistHandler) GetShoplistActivity(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetShoplistActivity: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	limit := bizshoplist.DefaultShoplistActivityPage
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > bizshoplist.MaxShoplistActivityPage {
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "limit")
+			return
+		}
+	}
+
+	beforeID := 0
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		beforeID, err = strconv.Atoi(cursorParam)
+		if err != nil || beforeID <= 0 {
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "cursor")
+			return
+		}
+	}
+
+	activities, shoplistErr := h.shoplistBiz.GetShoplistActivity(c, userID, shoplistID, beforeID, limit)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		default:
+			logger.Errorf("GetShoplistActivity: Failed to get activity. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	response := ActivityPageResponse{
+		Activities: make([]ActivityResponse, 0, len(activities)),
+	}
+	for _, activity := range activities {
+		response.Activities = append(response.Activities, ActivityResponse{
+			ID:     activity.ID,
+			Action: activity.Action,
+			Actor: OwnerResponse{
+				ID:       activity.ActorID,
+				Nickname: activity.ActorNickname,
+			},
+			ItemID:    activity.ItemID,
+			Before:    activityValuesResponse(activity.BeforeValues),
+			After:     activityValuesResponse(activity.AfterValues),
+			CreatedAt: activity.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	// A full page may be followed by more entries
+	if len(activities) == limit {
+		response.NextCursor = strconv.Itoa(activities[len(activities)-1].ID)
+	}
+
+	h.responseFactory.CreateOKResponse(c, response)
+}
+
+// activityValuesResponse passes the stored JSON values through, or null when there are none
+func activityValuesResponse(values string) json.RawMessage {
+	if values == "" {
+		return nil
+	}
+	return json.RawMessage(values)
+}
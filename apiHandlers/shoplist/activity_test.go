@@ -0,0 +1,142 @@
+package apiHandlersshoplist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestGetShoplistActivity(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "stranger-123", Nickname: "Stranger", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   users[0].ID,
+		Role:       "owner",
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Add, update and remove an item
+	body, _ := json.Marshal(map[string]interface{}{"item_name": "Milk", "quantity": 1})
+	req, _ := http.NewRequest("PUT", "/shoplist/1/items", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[0].ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.AddItemToShopList(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var item db.ShoplistItem
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).First(&item).Error
+	assert.NoError(t, err)
+	itemID := strconv.Itoa(item.ID)
+
+	body, _ = json.Marshal(map[string]interface{}{"quantity": 2})
+	req, _ = http.NewRequest("POST", "/shoplist/1/items/"+itemID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[0].ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: itemID}}
+
+	shoplistHandler.UpdateShoplistItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/shoplist/1/items/"+itemID, nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[0].ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: itemID}}
+
+	shoplistHandler.RemoveItemFromShopList(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	getActivity := func(userID string, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/shoplist/1/activity"+query, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.GetShoplistActivity(c)
+		return w
+	}
+
+	// Newest first, a full page has a cursor
+	w = getActivity(users[0].ID, "?limit=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var page ActivityPageResponse
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Activities))
+	assert.Equal(t, "item_removed", page.Activities[0].Action)
+	assert.Equal(t, "item_updated", page.Activities[1].Action)
+	assert.Equal(t, OwnerResponse{ID: users[0].ID, Nickname: "Owner"}, page.Activities[1].Actor)
+	assert.Equal(t, item.ID, *page.Activities[1].ItemID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	var before, after map[string]interface{}
+	assert.NoError(t, json.Unmarshal(page.Activities[1].Before, &before))
+	assert.NoError(t, json.Unmarshal(page.Activities[1].After, &after))
+	assert.Equal(t, float64(1), before["quantity"])
+	assert.Equal(t, float64(2), after["quantity"])
+
+	// The next page has the rest
+	w = getActivity(users[0].ID, "?limit=2&cursor="+page.NextCursor)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var nextPage ActivityPageResponse
+	err = json.Unmarshal(w.Body.Bytes(), &nextPage)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nextPage.Activities))
+	assert.Equal(t, "item_added", nextPage.Activities[0].Action)
+	assert.Equal(t, "null", string(nextPage.Activities[0].Before))
+	assert.Empty(t, nextPage.NextCursor)
+
+	// Invalid paging parameters
+	w = getActivity(users[0].ID, "?limit=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = getActivity(users[0].ID, "?cursor=abc")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Non members cannot read the activity
+	w = getActivity(users[1].ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,98 @@
+package apiHandlersshoplist
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// MarkAllItemsBought marks all items of a shoplist as bought
+// @Summary Mark all items as bought
+// @Description Marks every item of a shoplist that is not bought yet as bought. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Success 200 {object} map[string]interface{} "IDs of the items that were marked as bought"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/mark-all-bought [post]
+func (h *ShoplistHandler) MarkAllItemsBought(c *gin.Context) {
+	h.changeBoughtItems(c, "MarkAllItemsBought", h.shoplistBiz.MarkAllItemsBought)
+}
+
+// UnmarkAllItemsBought marks all items of a shoplist as not bought
+// @Summary Unmark all bought items
+// @Description Marks every bought item of a shoplist as not bought. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Success 200 {object} map[string]interface{} "IDs of the items that were marked as not bought"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/unmark-all-bought [post]
+func (h *ShoplistHandler) UnmarkAllItemsBought(c *gin.Context) {
+	h.changeBoughtItems(c, "UnmarkAllItemsBought", h.shoplistBiz.UnmarkAllItemsBought)
+}
+
+// ClearBoughtItems removes all bought items from a shoplist
+// @Summary Clear bought items
+// @Description Moves every bought item of a shoplist to the trash. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Success 200 {object} map[string]interface{} "IDs of the items that were removed"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/clear-bought [post]
+func (h *ShoplistHandler) ClearBoughtItems(c *gin.Context) {
+	h.changeBoughtItems(c, "ClearBoughtItems", h.shoplistBiz.ClearBoughtItems)
+}
+
+// changeBoughtItems runs a shoplist-level change of the bought items and responds with the IDs of the changed items
+func (h *ShoplistHandler) changeBoughtItems(c *gin.Context, handlerName string, change func(ctx context.Context, userID string, shoplistID int) ([]int, *bizshoplist.ShoplistError)) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("%s: User ID is empty.", handlerName)
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	itemIDs, shoplistErr := change(c, userID, shoplistID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
+		default:
+			logger.Errorf("%s: Failed to change items. Error: %s", handlerName, shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{
+		"item_ids": itemIDs,
+	})
+}
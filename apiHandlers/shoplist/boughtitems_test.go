@@ -0,0 +1,134 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestBoughtItemOperations(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "viewer-123", Nickname: "Viewer", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "viewer"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Add test items to shoplist
+	items := []db.ShoplistItem{
+		{ID: 1, ShopListID: testShoplist.ID, ItemName: "Milk", Position: 1, Version: 1},
+		{ID: 2, ShopListID: testShoplist.ID, ItemName: "Eggs", Position: 2, Version: 1, IsBought: true},
+		{ID: 3, ShopListID: testShoplist.ID, ItemName: "Bread", Position: 3, Version: 1},
+	}
+	for _, item := range items {
+		err := testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	changeItems := func(handler gin.HandlerFunc, userID string) (*httptest.ResponseRecorder, []int) {
+		req, _ := http.NewRequest("POST", "/shoplist/1/item/bought", nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("userID", userID)
+
+		handler(c)
+
+		var response struct {
+			ItemIDs []int `json:"item_ids"`
+		}
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+		}
+		return w, response.ItemIDs
+	}
+
+	getItems := func() []db.ShoplistItem {
+		var storedItems []db.ShoplistItem
+		err := testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).Order("id").Find(&storedItems).Error
+		assert.NoError(t, err)
+		return storedItems
+	}
+
+	// Viewers cannot change the items
+	w, _ := changeItems(shoplistHandler.MarkAllItemsBought, users[1].ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Only the items that were not bought change
+	w, itemIDs := changeItems(shoplistHandler.MarkAllItemsBought, users[0].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{1, 3}, itemIDs)
+	for _, item := range getItems() {
+		assert.True(t, item.IsBought)
+	}
+	assert.Equal(t, []int{2, 1, 2}, []int{getItems()[0].Version, getItems()[1].Version, getItems()[2].Version})
+
+	w, itemIDs = changeItems(shoplistHandler.UnmarkAllItemsBought, users[0].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{1, 2, 3}, itemIDs)
+	for _, item := range getItems() {
+		assert.False(t, item.IsBought)
+	}
+
+	// Clear the bought items
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("id = ?", 2).Update("is_bought", true).Error
+	assert.NoError(t, err)
+
+	w, itemIDs = changeItems(shoplistHandler.ClearBoughtItems, users[0].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{2}, itemIDs)
+	storedItems := getItems()
+	assert.Equal(t, 2, len(storedItems))
+	assert.Equal(t, "Milk", storedItems[0].ItemName)
+	assert.Equal(t, "Bread", storedItems[1].ItemName)
+
+	// Nothing left to clear
+	w, itemIDs = changeItems(shoplistHandler.ClearBoughtItems, users[0].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{}, itemIDs)
+
+	// Every change is recorded once with the user that made it
+	var activities []db.ShoplistActivity
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).Order("id").Find(&activities).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(activities))
+	assert.Equal(t, bizshoplist.ActivityItemsMarkedBought, activities[0].Action)
+	assert.Equal(t, bizshoplist.ActivityItemsUnmarked, activities[1].Action)
+	assert.Equal(t, bizshoplist.ActivityBoughtItemsCleared, activities[2].Action)
+	for _, activity := range activities {
+		assert.Equal(t, users[0].ID, activity.ActorID)
+	}
+	assert.JSONEq(t, `{"item_ids":[2]}`, activities[2].BeforeValues)
+}
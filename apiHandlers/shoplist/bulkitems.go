@@ -0,0 +1,329 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// AddItemsToShopList adds many items to a shoplist
+// @Summary Add many items to a shoplist
+// @Description Adds the items to the end of a shoplist in the order given, in one transaction. There is a result for every item in the same order, items that fail validation are reported with an error and not added. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+//
+//	@Param request body struct {
+//	    Items []struct {
+//	        ItemName  string  `json:"item_name"`
+//	        BrandName string  `json:"brand_name"`
+//	        ExtraInfo string  `json:"extra_info"`
+//	        Thumbnail string  `json:"thumbnail"`
+//	        Quantity  float64 `json:"quantity"`
+//	        Unit      string  `json:"unit"`
+//	        Category  string  `json:"category"`
+//	    } `json:"items"`
+//	} true "Items to add"
+//
+// @Success 200 {object} BulkItemsResponse "Results of the items"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Items are required"
+// @Failure 400 {object} map[string]string "Too many items"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/bulk [put]
+func (h *ShoplistHandler) AddItemsToShopList(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("AddItemsToShopList: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		Items []struct {
+			ItemName  string  `json:"item_name"`
+			BrandName string  `json:"brand_name"`
+			ExtraInfo string  `json:"extra_info"`
+			Thumbnail string  `json:"thumbnail"`
+			Quantity  float64 `json:"quantity"`
+			Unit      string  `json:"unit"`
+			Category  string  `json:"category"`
+		} `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil || len(requestBody.Items) == 0 {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "items")
+		return
+	}
+
+	items := make([]bizshoplist.NewShoplistItem, 0, len(requestBody.Items))
+	for _, item := range requestBody.Items {
+		items = append(items, bizshoplist.NewShoplistItem{
+			ItemName:  item.ItemName,
+			BrandName: item.BrandName,
+			ExtraInfo: item.ExtraInfo,
+			Thumbnail: item.Thumbnail,
+			Quantity:  item.Quantity,
+			Unit:      item.Unit,
+			Category:  item.Category,
+		})
+	}
+
+	results, shoplistErr := h.shoplistBiz.AddItemsToShopList(c, userID, shoplistID, items)
+	if shoplistErr != nil {
+		h.createBulkItemsErrorResponse(c, "AddItemsToShopList", shoplistErr)
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, bulkItemsResponse(results, nil))
+}
+
+// UpdateShoplistItems updates many items of a shoplist
+// @Summary Update many items of a shoplist
+// @Description Updates the items of a shoplist in one transaction. Every item needs its id and at least one of the fields (item_name, brand_name, extra_info, quantity, unit, category, is_bought). With a version the item is only updated if it did not change since that version. There is a result for every item in the same order, items that do not exist, fail validation or changed since their version are reported with an error and not updated. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+//
+//	@Param request body struct {
+//	    Items []struct {
+//	        ID        int      `json:"id"`
+//	        Version   int      `json:"version"`
+//	        ItemName  *string  `json:"item_name"`
+//	        BrandName *string  `json:"brand_name"`
+//	        ExtraInfo *string  `json:"extra_info"`
+//	        Quantity  *float64 `json:"quantity"`
+//	        Unit      *string  `json:"unit"`
+//	        Category  *string  `json:"category"`
+//	        IsBought  *bool    `json:"is_bought"`
+//	        Recurrence *string `json:"recurrence"`
+//	    } `json:"items"`
+//	} true "Item updates"
+//
+// @Success 200 {object} BulkItemsResponse "Results of the items"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Items are required"
+// @Failure 400 {object} map[string]string "Too many items"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/bulk [post]
+func (h *ShoplistHandler) UpdateShoplistItems(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("UpdateShoplistItems: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		Items []struct {
+			ID         int      `json:"id"`
+			Version    int      `json:"version"`
+			ItemName   *string  `json:"item_name"`
+			BrandName  *string  `json:"brand_name"`
+			ExtraInfo  *string  `json:"extra_info"`
+			Quantity   *float64 `json:"quantity"`
+			Unit       *string  `json:"unit"`
+			Category   *string  `json:"category"`
+			IsBought   *bool    `json:"is_bought"`
+			Recurrence *string  `json:"recurrence"`
+		} `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil || len(requestBody.Items) == 0 {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "items")
+		return
+	}
+
+	changes := make([]bizshoplist.ShoplistItemChange, 0, len(requestBody.Items))
+	itemIDs := make([]int, 0, len(requestBody.Items))
+	for _, item := range requestBody.Items {
+		changes = append(changes, bizshoplist.ShoplistItemChange{
+			ItemID: item.ID,
+			Fields: bizshoplist.ShoplistItemUpdate{
+				ItemName:   item.ItemName,
+				BrandName:  item.BrandName,
+				ExtraInfo:  item.ExtraInfo,
+				Quantity:   item.Quantity,
+				Unit:       item.Unit,
+				Category:   item.Category,
+				IsBought:   item.IsBought,
+				Recurrence: item.Recurrence,
+			},
+			ExpectedVersion: item.Version,
+		})
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	results, shoplistErr := h.shoplistBiz.UpdateShoplistItems(c, userID, shoplistID, changes)
+	if shoplistErr != nil {
+		h.createBulkItemsErrorResponse(c, "UpdateShoplistItems", shoplistErr)
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, bulkItemsResponse(results, itemIDs))
+}
+
+// RemoveItemsFromShopList removes many items from a shoplist
+// @Summary Remove many items from a shoplist
+// @Description Moves the items of a shoplist to the trash in one transaction. With a version the item is only removed if it did not change since that version. There is a result for every item in the same order, items that do not exist or changed since their version are reported with an error and not removed. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+//
+//	@Param request body struct {
+//	    Items []struct {
+//	        ID      int `json:"id"`
+//	        Version int `json:"version"`
+//	    } `json:"items"`
+//	} true "Items to remove"
+//
+// @Success 200 {object} BulkItemsResponse "Results of the items"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Items are required"
+// @Failure 400 {object} map[string]string "Too many items"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/bulk/delete [post]
+func (h *ShoplistHandler) RemoveItemsFromShopList(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("RemoveItemsFromShopList: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		Items []struct {
+			ID      int `json:"id"`
+			Version int `json:"version"`
+		} `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil || len(requestBody.Items) == 0 {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "items")
+		return
+	}
+
+	removals := make([]bizshoplist.ShoplistItemRemoval, 0, len(requestBody.Items))
+	itemIDs := make([]int, 0, len(requestBody.Items))
+	for _, item := range requestBody.Items {
+		removals = append(removals, bizshoplist.ShoplistItemRemoval{
+			ItemID:          item.ID,
+			ExpectedVersion: item.Version,
+		})
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	results, shoplistErr := h.shoplistBiz.RemoveItemsFromShopList(c, userID, shoplistID, removals)
+	if shoplistErr != nil {
+		h.createBulkItemsErrorResponse(c, "RemoveItemsFromShopList", shoplistErr)
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, bulkItemsResponse(results, itemIDs))
+}
+
+// createBulkItemsErrorResponse responds to a bulk request that failed as a whole
+func (h *ShoplistHandler) createBulkItemsErrorResponse(c *gin.Context, handlerName string, shoplistErr *bizshoplist.ShoplistError) {
+	switch shoplistErr.ErrCode {
+	case bizshoplist.ShoplistNotFound:
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+	case bizshoplist.ShoplistMemberReadOnly:
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
+	case bizshoplist.ShoplistInvalidBulkItems:
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "items")
+	case bizshoplist.ShoplistTooManyBulkItems:
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrTooManyBulkItems)
+	default:
+		logger.Errorf("%s: Failed to process items. Error: %s", handlerName, shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+	}
+}
+
+// bulkItemsResponse returns the results of a bulk request. itemIDs are the IDs in the request, nil for added items.
+func bulkItemsResponse(results []bizshoplist.BulkItemResult, itemIDs []int) BulkItemsResponse {
+	response := BulkItemsResponse{
+		Results: make([]BulkItemResultResponse, 0, len(results)),
+	}
+
+	for i, result := range results {
+		resultResponse := BulkItemResultResponse{}
+		if itemIDs != nil {
+			resultResponse.ID = itemIDs[i]
+		}
+
+		if result.Err != nil {
+			resultResponse.Code = bulkItemErrorCode(result.Err)
+			resultResponse.Error = result.Err.Message
+		} else if result.Item != nil {
+			resultResponse.ID = result.Item.ID
+			resultResponse.Item = syncItemResponse(*result.Item)
+		}
+
+		response.Results = append(response.Results, resultResponse)
+	}
+
+	return response
+}
+
+// bulkItemErrorCode returns the response code for an item of a bulk request that was not applied
+func bulkItemErrorCode(shoplistErr *bizshoplist.ShoplistError) string {
+	switch shoplistErr.ErrCode {
+	case bizshoplist.ShoplistItemNotFound:
+		return apiHandlers.ErrShoplistItemNotFound
+	case bizshoplist.ShoplistItemNameEmpty:
+		return apiHandlers.ErrMissingRequiredField
+	case bizshoplist.ShoplistItemNoChanges:
+		return apiHandlers.ErrMissingRequiredFieldUpdateShoplistItem
+	case bizshoplist.ShoplistItemInvalidUnit:
+		return apiHandlers.ErrInvalidShoplistItemUnit
+	case bizshoplist.ShoplistItemInvalidQuantity:
+		return apiHandlers.ErrInvalidShoplistItemQuantity
+	case bizshoplist.ShoplistItemInvalidCategory:
+		return apiHandlers.ErrInvalidShoplistItemCategory
+	case bizshoplist.ShoplistItemInvalidRecurrence:
+		return apiHandlers.ErrInvalidShoplistItemRecurrence
+	case bizshoplist.ShoplistVersionConflict:
+		return apiHandlers.ErrShoplistVersionConflict
+	default:
+		return apiHandlers.ErrInternalServerError
+	}
+}
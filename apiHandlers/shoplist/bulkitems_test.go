@@ -0,0 +1,137 @@
+package apiHandlersshoplist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/apiHandlers"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestBulkItemOperations(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "viewer-123", Nickname: "Viewer", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "viewer"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	bulkRequest := func(handler gin.HandlerFunc, userID string, items []map[string]interface{}) (*httptest.ResponseRecorder, BulkItemsResponse) {
+		body, _ := json.Marshal(map[string]interface{}{"items": items})
+		req, _ := http.NewRequest("POST", "/shoplist/1/item/bulk", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("userID", userID)
+
+		handler(c)
+
+		var response BulkItemsResponse
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+		}
+		return w, response
+	}
+
+	// Add items, the invalid one is reported and skipped
+	w, response := bulkRequest(shoplistHandler.AddItemsToShopList, users[0].ID, []map[string]interface{}{
+		{"item_name": "Milk", "quantity": 2, "unit": "l"},
+		{"item_name": "Flour", "quantity": 1, "unit": "lbs"},
+		{"item_name": "Eggs", "quantity": 12, "unit": "each"},
+		{"item_name": ""},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, len(response.Results))
+	assert.Equal(t, "Milk", response.Results[0].Item.Name)
+	assert.Equal(t, "L", response.Results[0].Item.Unit)
+	assert.Equal(t, 1, response.Results[0].Item.Position)
+	assert.Nil(t, response.Results[1].Item)
+	assert.Equal(t, apiHandlers.ErrInvalidShoplistItemUnit, response.Results[1].Code)
+	assert.Equal(t, "Eggs", response.Results[2].Item.Name)
+	assert.Equal(t, 2, response.Results[2].Item.Position)
+	assert.Equal(t, apiHandlers.ErrMissingRequiredField, response.Results[3].Code)
+
+	milkID := response.Results[0].ID
+	eggsID := response.Results[2].ID
+
+	// Update items, with and without a version
+	w, response = bulkRequest(shoplistHandler.UpdateShoplistItems, users[0].ID, []map[string]interface{}{
+		{"id": milkID, "version": 1, "is_bought": true},
+		{"id": eggsID, "version": 5, "is_bought": true},
+		{"id": 999, "is_bought": true},
+		{"id": eggsID},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, len(response.Results))
+	assert.Equal(t, milkID, response.Results[0].ID)
+	assert.True(t, response.Results[0].Item.IsBought)
+	assert.Equal(t, 2, response.Results[0].Item.Version)
+	assert.Equal(t, apiHandlers.ErrShoplistVersionConflict, response.Results[1].Code)
+	assert.Equal(t, eggsID, response.Results[1].ID)
+	assert.Equal(t, apiHandlers.ErrShoplistItemNotFound, response.Results[2].Code)
+	assert.Equal(t, apiHandlers.ErrMissingRequiredFieldUpdateShoplistItem, response.Results[3].Code)
+
+	// Remove items
+	w, response = bulkRequest(shoplistHandler.RemoveItemsFromShopList, users[0].ID, []map[string]interface{}{
+		{"id": milkID, "version": 1},
+		{"id": eggsID},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, apiHandlers.ErrShoplistVersionConflict, response.Results[0].Code)
+	assert.Equal(t, "", response.Results[1].Code)
+	assert.Equal(t, eggsID, response.Results[1].ID)
+
+	var storedItems []db.ShoplistItem
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).Find(&storedItems).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(storedItems))
+	assert.Equal(t, milkID, storedItems[0].ID)
+
+	// Viewers cannot change items
+	w, _ = bulkRequest(shoplistHandler.AddItemsToShopList, users[1].ID, []map[string]interface{}{{"item_name": "Bread"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Empty and too large requests
+	w, _ = bulkRequest(shoplistHandler.RemoveItemsFromShopList, users[0].ID, []map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	tooMany := make([]map[string]interface{}, 0, 101)
+	for i := 0; i <= 100; i++ {
+		tooMany = append(tooMany, map[string]interface{}{"item_name": "Item " + strconv.Itoa(i)})
+	}
+	w, _ = bulkRequest(shoplistHandler.AddItemsToShopList, users[0].ID, tooMany)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
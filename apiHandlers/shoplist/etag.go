@@ -0,0 +1,35 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// formatETag returns the ETag of a version of a shoplist or item
+func formatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch returns the version from the If-Match header. A missing header or * matches any version.
+// The second return value is false when the header is not an ETag returned by formatETag.
+func parseIfMatch(c *gin.Context) (int, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return bizshoplist.AnyVersion, true
+	}
+
+	if len(ifMatch) < 3 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
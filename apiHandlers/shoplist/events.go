@@ -0,0 +1,119 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// shoplistEventKeepAlive is how often a comment is sent on an idle stream so that proxies keep the connection open
+const shoplistEventKeepAlive = 25 * time.Second
+
+// StreamShoplistEvents streams the changes of a shoplist
+// @Summary Stream shoplist changes
+// @Description Streams the changes of a shoplist as Server-Sent Events while the connection is open. The event name is the activity action and the data has the same fields as an entry of the activity log. The stream ends when the user leaves or is removed from the shoplist, or when the shoplist is deleted.
+// @Tags shoplist
+// @Produce text/event-stream
+// @Param id path int true "Shoplist ID"
+// @Success 200 {object} ShoplistEventResponse "Stream of shoplist events"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/events [get]
+func (h *ShoplistHandler) StreamShoplistEvents(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("StreamShoplistEvents: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	events, unsubscribe, shoplistErr := h.shoplistBiz.SubscribeShoplistEvents(c, userID, shoplistID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		default:
+			logger.Errorf("StreamShoplistEvents: Failed to subscribe. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(shoplistEventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			// the broker closes the channel of a subscriber that falls behind, the client reconnects and refetches
+			if !ok {
+				return
+			}
+
+			if err := writeShoplistEvent(c, event); err != nil {
+				logger.Errorf("StreamShoplistEvents: Failed to write event. Error: %s", err.Error())
+				return
+			}
+
+			if event.EndsSubscription(userID) {
+				return
+			}
+		}
+	}
+}
+
+// writeShoplistEvent writes an event in the Server-Sent Events format and flushes it to the client
+func writeShoplistEvent(c *gin.Context, event bizshoplist.ShoplistEvent) error {
+	data, err := json.Marshal(ShoplistEventResponse{
+		ID:         event.ID,
+		ShoplistID: event.ShopListID,
+		Action:     event.Action,
+		ActorID:    event.ActorID,
+		ItemID:     event.ItemID,
+		Before:     activityValuesResponse(event.BeforeValues),
+		After:      activityValuesResponse(event.AfterValues),
+		CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Action, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
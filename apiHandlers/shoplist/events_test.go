@@ -0,0 +1,95 @@
+package apiHandlersshoplist
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestStreamShoplistEvents(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "member-123", Nickname: "Member", PostalCode: "238802"},
+		{ID: "stranger-123", Nickname: "Stranger", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "editor"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Serve the stream so that the response can be read while it is written
+	router := gin.New()
+	router.GET("/shoplist/:id/events", func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-Test-User"))
+		shoplistHandler.StreamShoplistEvents(c)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	openStream := func(ctx context.Context, userID string) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/shoplist/1/events", nil)
+		req.Header.Set("X-Test-User", userID)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Non members cannot watch the shoplist
+	resp := openStream(context.Background(), users[2].ID)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp = openStream(ctx, users[1].ID)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The owner adds an item, then removes the member
+	_, shoplistErr := shoplistHandler.shoplistBiz.AddItemToShopList(ctx, users[0].ID, testShoplist.ID, "Milk", "", "", "", 1, "", "")
+	assert.Nil(t, shoplistErr)
+	shoplistErr = shoplistHandler.shoplistBiz.RemoveShoplistMember(ctx, users[0].ID, testShoplist.ID, users[1].ID, false)
+	assert.Nil(t, shoplistErr)
+
+	// The member sees both events, then the stream ends
+	events := make([]string, 0)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, found := strings.CutPrefix(scanner.Text(), "event: "); found {
+			events = append(events, name)
+		}
+	}
+	assert.Equal(t, []string{"item_added", "member_removed"}, events)
+}
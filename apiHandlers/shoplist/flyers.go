@@ -0,0 +1,67 @@
+package apiHandlersshoplist
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	bizmodels "netherealmstudio.com/m/v2/biz"
+)
+
+// includeFlyers is the include option that matches the items of a shoplist read with flyers
+const includeFlyers = "flyers"
+
+// parseIncludeFlyers reads the comma separated include query option. It returns whether flyers are included and false
+// when the option has an unknown value.
+func parseIncludeFlyers(c *gin.Context) (bool, bool) {
+	include := c.Query("include")
+	if include == "" {
+		return false, true
+	}
+
+	withFlyers := false
+	for _, option := range strings.Split(include, ",") {
+		if strings.TrimSpace(option) != includeFlyers {
+			return false, false
+		}
+		withFlyers = true
+	}
+
+	return withFlyers, true
+}
+
+// matchFlyers matches the items with flyers when they are included. Flyers are optional on a shoplist read, so when
+// matching fails the error is logged and the read is reported as degraded instead of failing.
+func (h *ShoplistHandler) matchFlyers(c *gin.Context, handlerName string, items []bizmodels.ShoplistItem, withFlyers bool) (map[int][]*bizmodels.Flyer, bool) {
+	if !withFlyers || len(items) == 0 {
+		return nil, false
+	}
+
+	flyers, err := h.matchBiz.MatchShoplistItemsWithFlyer(c.Request.Context(), items)
+	if err != nil {
+		logger.Errorf("%s: Failed to match shoplist items with flyers. Error: %s", handlerName, err.Error())
+		return nil, true
+	}
+
+	return flyers, false
+}
+
+// flyerResponses transforms the flyers matched with an item into the response format
+func flyerResponses(flyers []*bizmodels.Flyer) []FlyerResponse {
+	flyerResp := make([]FlyerResponse, 0, len(flyers))
+	for _, flyer := range flyers {
+		flyerResp = append(flyerResp, FlyerResponse{
+			Store:         flyer.Store,
+			Brand:         flyer.Brand,
+			StartDate:     flyer.StartDateTime,
+			EndDate:       flyer.EndDateTime,
+			ProductName:   flyer.ProductName,
+			Description:   flyer.Description,
+			OriginalPrice: flyer.OriginalPrice,
+			PrePriceText:  flyer.PrePriceText,
+			PriceText:     flyer.PriceText,
+			PostPriceText: flyer.PostPriceText,
+		})
+	}
+	return flyerResp
+}
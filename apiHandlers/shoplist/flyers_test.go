@@ -0,0 +1,82 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/elasticsearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bizmatch "netherealmstudio.com/m/v2/biz/match"
+	dbmodel "netherealmstudio.com/m/v2/db"
+)
+
+func TestShoplistReadFlyersDegraded(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Point flyer matching at an Elasticsearch that is not running
+	esc, err := elasticsearch.NewElasticsearchClient("localhost", "1")
+	require.NoError(t, err)
+	shoplistHandler.matchBiz = bizmatch.NewMatchShoplistItemsWithFlyerBiz(esc, testConn)
+
+	owner := dbmodel.User{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"}
+	assert.NoError(t, testConn.GetDB().Create(&owner).Error)
+	assert.NoError(t, testConn.GetDB().Create(&dbmodel.Shoplist{ID: 1, OwnerID: owner.ID, Name: "Shoplist 1"}).Error)
+	assert.NoError(t, testConn.GetDB().Create(&dbmodel.ShoplistMember{ShopListID: 1, MemberID: owner.ID}).Error)
+	assert.NoError(t, testConn.GetDB().Create(&dbmodel.ShoplistItem{ID: 1, ShopListID: 1, ItemName: "Milk"}).Error)
+
+	read := func(handler gin.HandlerFunc, query string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/shoplist?"+query, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("userID", owner.ID)
+
+		handler(c)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	// Test case 1: Without include=flyers the items are not matched
+	{
+		code, response := read(shoplistHandler.GetAllShoplistAndItemsForUser, "")
+		assert.Equal(t, http.StatusOK, code)
+		_, degraded := response["degraded"]
+		assert.False(t, degraded)
+		item := response["shoplists"].([]interface{})[0].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{}, item["flyer"])
+
+		code, response = read(shoplistHandler.GetShoplistAndItemsForUserByShoplistID, "")
+		assert.Equal(t, http.StatusOK, code)
+		_, degraded = response["degraded"]
+		assert.False(t, degraded)
+	}
+
+	// Test case 2: With include=flyers a failed match returns the shoplists as degraded
+	{
+		code, response := read(shoplistHandler.GetAllShoplistAndItemsForUser, "include=flyers")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, true, response["degraded"])
+		assert.Len(t, response["shoplists"], 1)
+
+		code, response = read(shoplistHandler.GetShoplistAndItemsForUserByShoplistID, "include=flyers")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, true, response["degraded"])
+		assert.Len(t, response["items"], 1)
+	}
+
+	// Test case 3: Unknown include options are rejected
+	{
+		code, _ := read(shoplistHandler.GetAllShoplistAndItemsForUser, "include=prices")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = read(shoplistHandler.GetShoplistAndItemsForUserByShoplistID, "include=flyers,prices")
+		assert.Equal(t, http.StatusBadRequest, code)
+	}
+}
@@ -0,0 +1,204 @@
+package apiHandlersshoplist
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// InviteToShoplist invites a user to a shoplist
+// @Summary Invite a user
+// @Description Creates a pending invitation for a user to join a shoplist with the given role. The invitee can accept or decline the invitation. Only the owner can invite users.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param invitee_id body string true "User to invite"
+// @Param role body string false "Role granted on accept, editor or viewer"
+// @Success 200 {object} map[string]interface{} "Successfully invited the user"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invitee is required"
+// @Failure 400 {object} map[string]string "Role must be editor or viewer"
+// @Failure 400 {object} map[string]string "User is already a member of the shoplist"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "Only the owner can invite users"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 404 {object} map[string]string "Invitee not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/invitations [post]
+func (h *ShoplistHandler) InviteToShoplist(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("InviteToShoplist: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		InviteeID string `json:"invitee_id" binding:"required"`
+		Role      string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "invitee_id")
+		return
+	}
+
+	invitation, shoplistErr := h.shoplistBiz.InviteToShoplist(c, userID, shoplistID, requestBody.InviteeID, requestBody.Role)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		case bizshoplist.ShoplistInvalidMemberRole:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistMemberRole)
+		case bizshoplist.ShoplistAlreadyMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistAlreadyMember)
+		case bizshoplist.ShoplistInviteeNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistInviteeNotFound)
+		default:
+			logger.Errorf("InviteToShoplist: Failed to invite user. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{
+		"id":         invitation.ID,
+		"invitee_id": invitation.InviteeID,
+		"role":       invitation.Role,
+		"status":     invitation.Status,
+	})
+}
+
+// GetShoplistInvitations returns the pending invitations of a user
+// @Summary Get pending invitations
+// @Description Returns the pending invitations of the user to join shoplists
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Successfully got invitations"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/invitations [get]
+func (h *ShoplistHandler) GetShoplistInvitations(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetShoplistInvitations: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	invitations, shoplistErr := h.shoplistBiz.GetShoplistInvitations(c, userID)
+	if shoplistErr != nil {
+		logger.Errorf("GetShoplistInvitations: Failed to get invitations. Error: %s", shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	responseInvitations := make([]map[string]interface{}, 0, len(invitations))
+	for _, invitation := range invitations {
+		responseInvitations = append(responseInvitations, map[string]interface{}{
+			"id":            invitation.ID,
+			"shoplist_id":   invitation.ShopListID,
+			"shoplist_name": invitation.ShopListName,
+			"invited_by": map[string]string{
+				"id":       invitation.InvitedBy,
+				"nickname": invitation.InvitedByNickname,
+			},
+			"role":       invitation.Role,
+			"invited_at": invitation.InvitedAt.Format(time.RFC3339),
+		})
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{
+		"invitations": responseInvitations,
+	})
+}
+
+// AcceptShoplistInvitation accepts a pending invitation
+// @Summary Accept an invitation
+// @Description Accepts a pending invitation and joins the shoplist with the role of the invitation
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param invitationId path int true "Invitation ID"
+// @Success 200 {object} gin.H "Successfully accepted the invitation"
+// @Failure 400 {object} map[string]string "Invalid invitation ID"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "User is not allowed to join this shoplist"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/invitations/{invitationId}/accept [post]
+func (h *ShoplistHandler) AcceptShoplistInvitation(c *gin.Context) {
+	h.answerShoplistInvitation(c, "AcceptShoplistInvitation", h.shoplistBiz.AcceptShoplistInvitation)
+}
+
+// DeclineShoplistInvitation declines a pending invitation
+// @Summary Decline an invitation
+// @Description Declines a pending invitation
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param invitationId path int true "Invitation ID"
+// @Success 200 {object} gin.H "Successfully declined the invitation"
+// @Failure 400 {object} map[string]string "Invalid invitation ID"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/invitations/{invitationId}/decline [post]
+func (h *ShoplistHandler) DeclineShoplistInvitation(c *gin.Context) {
+	h.answerShoplistInvitation(c, "DeclineShoplistInvitation", h.shoplistBiz.DeclineShoplistInvitation)
+}
+
+// answerShoplistInvitation handles the shared request and error handling of accepting and declining invitations
+func (h *ShoplistHandler) answerShoplistInvitation(c *gin.Context, name string, answer func(ctx context.Context, userID string, invitationID int) *bizshoplist.ShoplistError) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("%s: User ID is empty.", name)
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get invitation ID from URL
+	invitationID, err := strconv.Atoi(c.Param("invitationId"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "invitationId")
+		return
+	}
+
+	shoplistErr := answer(c, userID, invitationID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistInvitationNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistInvitationNotFound)
+		case bizshoplist.ShoplistMemberBanned:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberBanned)
+		default:
+			logger.Errorf("%s: Failed to answer invitation. Error: %s", name, shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
@@ -0,0 +1,273 @@
+package apiHandlersshoplist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestShoplistInvitationFlow(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "invitee1-123", Nickname: "Invitee 1", PostalCode: "238802"},
+		{ID: "invitee2-123", Nickname: "Invitee 2", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   users[0].ID,
+		Role:       "owner",
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	invite := func(userID string, inviteeID string, role string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"invitee_id": inviteeID, "role": role})
+		req, _ := http.NewRequest("POST", "/shoplist/1/invitations", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.InviteToShoplist(c)
+		return w
+	}
+
+	answer := func(userID string, invitationID int, accept bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/shoplist/invitations/"+strconv.Itoa(invitationID), nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "invitationId", Value: strconv.Itoa(invitationID)}}
+
+		if accept {
+			shoplistHandler.AcceptShoplistInvitation(c)
+		} else {
+			shoplistHandler.DeclineShoplistInvitation(c)
+		}
+		return w
+	}
+
+	// Unknown invitee
+	w := invite(users[0].ID, "stranger-123", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Owner is already a member
+	w = invite(users[0].ID, users[0].ID, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Invite both users
+	w = invite(users[0].ID, users[1].ID, "viewer")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "viewer", response["role"])
+	assert.Equal(t, "pending", response["status"])
+	invitation1ID := int(response["id"].(float64))
+
+	w = invite(users[0].ID, users[2].ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response2 map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response2)
+	assert.NoError(t, err)
+	invitation2ID := int(response2["id"].(float64))
+
+	// Invitee lists the pending invitation
+	req, _ := http.NewRequest("GET", "/shoplist/invitations", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	w = httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[1].ID)
+
+	shoplistHandler.GetShoplistInvitations(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var invitationsResponse map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &invitationsResponse)
+	assert.NoError(t, err)
+	invitations := invitationsResponse["invitations"].([]interface{})
+	assert.Equal(t, 1, len(invitations))
+	invitation := invitations[0].(map[string]interface{})
+	assert.Equal(t, float64(invitation1ID), invitation["id"])
+	assert.Equal(t, "Test Shoplist", invitation["shoplist_name"])
+	assert.Equal(t, map[string]interface{}{"id": users[0].ID, "nickname": "Owner"}, invitation["invited_by"])
+
+	// Members show the pending invitees separately
+	req, _ = http.NewRequest("GET", "/shoplist/1/members", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[0].ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.GetShoplistMembers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var membersResponse map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &membersResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(membersResponse["members"].([]interface{})))
+	assert.Equal(t, 2, len(membersResponse["pending_invitees"].([]interface{})))
+
+	// Other users cannot answer the invitation
+	w = answer(users[2].ID, invitation1ID, true)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Accept joins the shoplist with the invited role
+	w = answer(users[1].ID, invitation1ID, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var member db.ShoplistMember
+	err = testConn.GetDB().Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[1].ID).First(&member).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "viewer", member.Role)
+
+	// An answered invitation cannot be answered again
+	w = answer(users[1].ID, invitation1ID, false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Decline does not join the shoplist
+	w = answer(users[2].ID, invitation2ID, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[2].ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	var invitationRecord db.ShoplistInvitation
+	err = testConn.GetDB().First(&invitationRecord, invitation2ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "declined", invitationRecord.Status)
+}
+
+func TestAcceptShoplistInvitationBanned(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+	ctx := context.Background()
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member-123", PostalCode: "238802"},
+		{ID: "banned-123", PostalCode: "238803"},
+		{ID: "invitee-123", PostalCode: "238804"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist with two members
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "editor"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// The owner banned a user, who still holds an invitation from a former owner
+	err = testConn.GetDB().Create(&db.ShoplistBan{ShopListID: testShoplist.ID, MemberID: users[2].ID, BannedBy: users[0].ID}).Error
+	assert.NoError(t, err)
+	staleInvitation := db.ShoplistInvitation{ShopListID: testShoplist.ID, InviteeID: users[2].ID, InvitedBy: users[1].ID, Role: "editor", Status: "pending"}
+	err = testConn.GetDB().Create(&staleInvitation).Error
+	assert.NoError(t, err)
+
+	accept := func(userID string, invitationID int) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/shoplist/invitations/"+strconv.Itoa(invitationID)+"/accept", nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "invitationId", Value: strconv.Itoa(invitationID)}}
+
+		shoplistHandler.AcceptShoplistInvitation(c)
+		return w
+	}
+
+	countRows := func(model interface{}, memberID string) int64 {
+		var count int64
+		err := testConn.GetDB().Model(model).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, memberID).Count(&count).Error
+		assert.NoError(t, err)
+		return count
+	}
+
+	// An invitation from someone other than the owner does not lift the ban
+	w := accept(users[2].ID, staleInvitation.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00012",
+		"error": "User is not allowed to join this shoplist.",
+	}, response)
+	assert.Equal(t, int64(1), countRows(&db.ShoplistBan{}, users[2].ID))
+	assert.Equal(t, int64(0), countRows(&db.ShoplistMember{}, users[2].ID))
+
+	// An invitation from the owner lifts the ban
+	invitation, shoplistErr := shoplistHandler.shoplistBiz.InviteToShoplist(ctx, users[0].ID, testShoplist.ID, users[2].ID, "")
+	assert.Nil(t, shoplistErr)
+	w = accept(users[2].ID, invitation.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(0), countRows(&db.ShoplistBan{}, users[2].ID))
+	assert.Equal(t, int64(1), countRows(&db.ShoplistMember{}, users[2].ID))
+
+	// Transferring the ownership drops the pending invitations of the previous owner
+	invitation, shoplistErr = shoplistHandler.shoplistBiz.InviteToShoplist(ctx, users[0].ID, testShoplist.ID, users[3].ID, "")
+	assert.Nil(t, shoplistErr)
+	shoplistErr = shoplistHandler.shoplistBiz.TransferShoplistOwnership(ctx, users[0].ID, testShoplist.ID, users[1].ID)
+	assert.Nil(t, shoplistErr)
+
+	w = accept(users[3].ID, invitation.ID)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, int64(0), countRows(&db.ShoplistMember{}, users[3].ID))
+}
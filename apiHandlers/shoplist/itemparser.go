@@ -0,0 +1,38 @@
+package apiHandlersshoplist
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// ParseItemText previews how the text of an item is parsed
+// @Summary Preview item parsing
+// @Description Returns the name, brand, quantity, unit and category read from the free text of an item, as in "2 lbs Maple Leaf chicken breast". Nothing is saved; add the item with parse set to store it this way.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param text query string true "Free text of the item"
+// @Success 200 {object} ParsedItemResponse "Successfully parsed item"
+// @Failure 400 {object} map[string]string "Missing text"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Router /shoplist/item/parse [get]
+func (h *ShoplistHandler) ParseItemText(c *gin.Context) {
+	text := c.Query("text")
+	if strings.TrimSpace(text) == "" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "text")
+		return
+	}
+
+	parsed := bizshoplist.ParseItemText(text)
+	h.responseFactory.CreateOKResponse(c, ParsedItemResponse{
+		ItemName:  parsed.ItemName,
+		BrandName: parsed.BrandName,
+		Quantity:  parsed.Quantity,
+		Unit:      parsed.Unit,
+		Category:  parsed.Category,
+	})
+}
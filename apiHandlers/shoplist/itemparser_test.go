@@ -0,0 +1,46 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/apiHandlers"
+)
+
+func TestParseItemText(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	shoplistHandler := &ShoplistHandler{responseFactory: apiHandlers.ResponseFactory{}}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/v2/shoplist/item/parse?text=2+lbs+Maple+Leaf+chicken+breast", nil)
+	c.Set("userID", "owner-123")
+
+	shoplistHandler.ParseItemText(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"item_name":  "chicken breast",
+		"brand_name": "Maple Leaf",
+		"quantity":   float64(907),
+		"unit":       "g",
+		"category":   "meat_seafood",
+	}, response)
+
+	// The text is required
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/v2/shoplist/item/parse?text=+", nil)
+	c.Set("userID", "owner-123")
+
+	shoplistHandler.ParseItemText(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
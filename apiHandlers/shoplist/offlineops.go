@@ -0,0 +1,164 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// ReplayOfflineOps applies the operations a client queued while it was offline
+// @Summary Replay offline operations
+// @Description Applies an ordered batch of operations that a client queued while it was offline, in one transaction. The type of an operation is add_item, check_item, rename_item or delete_item. Items added offline need a client_item_id, later operations in the same or a later batch can refer to the item by that ID instead of item_id. Every operation gets a result in the same order: applied, duplicate when an earlier replay applied it already, conflict when somebody else changed or deleted the item after the operation's timestamp, or rejected when the operation is invalid. The user must be a member of the shoplist that can edit items.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+//
+//	@Param request body struct {
+//	    Ops []struct {
+//	        OpID         string    `json:"op_id"`
+//	        Type         string    `json:"type"`
+//	        Timestamp    time.Time `json:"timestamp"`
+//	        ItemID       int       `json:"item_id"`
+//	        ClientItemID string    `json:"client_item_id"`
+//	        ItemName     string    `json:"item_name"`
+//	        BrandName    string    `json:"brand_name"`
+//	        ExtraInfo    string    `json:"extra_info"`
+//	        Quantity     float64   `json:"quantity"`
+//	        Unit         string    `json:"unit"`
+//	        Category     string    `json:"category"`
+//	        IsBought     bool      `json:"is_bought"`
+//	    } `json:"ops"`
+//	} true "Operations in the order they were made"
+//
+// @Success 200 {object} ReplayResponse "Results of the operations"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Operations are required"
+// @Failure 400 {object} map[string]string "Too many operations"
+// @Failure 403 {object} map[string]string "Viewers cannot modify items"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/replay [post]
+func (h *ShoplistHandler) ReplayOfflineOps(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("ReplayOfflineOps: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		Ops []struct {
+			OpID         string    `json:"op_id"`
+			Type         string    `json:"type"`
+			Timestamp    time.Time `json:"timestamp"`
+			ItemID       int       `json:"item_id"`
+			ClientItemID string    `json:"client_item_id"`
+			ItemName     string    `json:"item_name"`
+			BrandName    string    `json:"brand_name"`
+			ExtraInfo    string    `json:"extra_info"`
+			Quantity     float64   `json:"quantity"`
+			Unit         string    `json:"unit"`
+			Category     string    `json:"category"`
+			IsBought     bool      `json:"is_bought"`
+		} `json:"ops"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidRequestBody)
+		return
+	}
+	if len(requestBody.Ops) == 0 {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "ops")
+		return
+	}
+
+	ops := make([]bizshoplist.OfflineOp, 0, len(requestBody.Ops))
+	for _, op := range requestBody.Ops {
+		ops = append(ops, bizshoplist.OfflineOp{
+			OpID:         op.OpID,
+			Type:         op.Type,
+			Timestamp:    op.Timestamp,
+			ItemID:       op.ItemID,
+			ClientItemID: op.ClientItemID,
+			ItemName:     op.ItemName,
+			BrandName:    op.BrandName,
+			ExtraInfo:    op.ExtraInfo,
+			Quantity:     op.Quantity,
+			Unit:         op.Unit,
+			Category:     op.Category,
+			IsBought:     op.IsBought,
+		})
+	}
+
+	results, shoplistErr := h.shoplistBiz.ReplayOfflineOps(c, userID, shoplistID, ops)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
+		case bizshoplist.ShoplistInvalidOfflineOp:
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "ops")
+		case bizshoplist.ShoplistTooManyOfflineOps:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrTooManyOfflineOps)
+		default:
+			logger.Errorf("ReplayOfflineOps: Failed to replay operations. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	response := ReplayResponse{
+		Results: make([]ReplayResultResponse, 0, len(results)),
+	}
+	for _, result := range results {
+		resultResponse := ReplayResultResponse{
+			OpID:         result.OpID,
+			Status:       result.Status,
+			Error:        result.Message,
+			ItemID:       result.ItemID,
+			ClientItemID: result.ClientItemID,
+		}
+		if result.Item != nil {
+			resultResponse.Item = syncItemResponse(*result.Item)
+		}
+		response.Results = append(response.Results, resultResponse)
+	}
+
+	h.responseFactory.CreateOKResponse(c, response)
+}
+
+// syncItemResponse returns an item in the same shape as the items of a sync, so that clients can apply it the same way
+func syncItemResponse(item db.ShoplistItem) *SyncItemResponse {
+	return &SyncItemResponse{
+		ID:         item.ID,
+		ShoplistID: item.ShopListID,
+		Name:       item.ItemName,
+		BrandName:  item.BrandName,
+		ExtraInfo:  item.ExtraInfo,
+		Quantity:   item.Quantity,
+		Unit:       item.Unit,
+		Category:   item.Category,
+		IsBought:   item.IsBought,
+		Position:   item.Position,
+		Thumbnail:  item.Thumbnail,
+		Version:    item.Version,
+		Recurrence: item.Recurrence,
+		UpdatedAt:  item.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,147 @@
+package apiHandlersshoplist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestReplayOfflineOps(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "viewer-123", Nickname: "Viewer", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "viewer"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Bread was last changed before the client went offline, eggs were changed by somebody else since
+	hourAgo := time.Now().Add(-time.Hour)
+	items := []db.ShoplistItem{
+		{Model: gorm.Model{CreatedAt: hourAgo, UpdatedAt: hourAgo}, ID: 1, ShopListID: testShoplist.ID, ItemName: "Bread", Position: 1, Version: 1},
+		{ID: 2, ShopListID: testShoplist.ID, ItemName: "Eggs", Position: 2, Version: 1},
+	}
+	for _, item := range items {
+		err := testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	replay := func(userID string, ops []map[string]interface{}) (*httptest.ResponseRecorder, ReplayResponse) {
+		body, _ := json.Marshal(map[string]interface{}{"ops": ops})
+		req, _ := http.NewRequest("POST", "/shoplist/1/replay", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+		c.Set("userID", userID)
+
+		shoplistHandler.ReplayOfflineOps(c)
+
+		var response ReplayResponse
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+		}
+		return w, response
+	}
+
+	opTime := time.Now().Add(-30 * time.Minute).Format(time.RFC3339)
+	addMilk := map[string]interface{}{"op_id": "op-1", "type": "add_item", "timestamp": opTime, "client_item_id": "client-milk", "item_name": "Milk", "quantity": 2, "unit": "l"}
+	ops := []map[string]interface{}{
+		addMilk,
+		{"op_id": "op-2", "type": "check_item", "timestamp": opTime, "client_item_id": "client-milk", "is_bought": true},
+		{"op_id": "op-3", "type": "rename_item", "timestamp": opTime, "item_id": 1, "item_name": "  Rye Bread "},
+		{"op_id": "op-4", "type": "check_item", "timestamp": opTime, "item_id": 2, "is_bought": true},
+		{"op_id": "op-5", "type": "delete_item", "timestamp": opTime, "item_id": 99},
+	}
+
+	w, response := replay(users[0].ID, ops)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, len(response.Results))
+
+	// The item added offline gets a server ID that later operations resolve through the client ID
+	milk := response.Results[0]
+	assert.Equal(t, "op-1", milk.OpID)
+	assert.Equal(t, bizshoplist.OfflineOpApplied, milk.Status)
+	assert.Equal(t, "client-milk", milk.ClientItemID)
+	assert.NotZero(t, milk.ItemID)
+	assert.Equal(t, "L", milk.Item.Unit)
+	assert.Equal(t, 3, milk.Item.Position)
+
+	assert.Equal(t, bizshoplist.OfflineOpApplied, response.Results[1].Status)
+	assert.Equal(t, milk.ItemID, response.Results[1].ItemID)
+	assert.True(t, response.Results[1].Item.IsBought)
+	assert.Equal(t, 2, response.Results[1].Item.Version)
+
+	assert.Equal(t, bizshoplist.OfflineOpApplied, response.Results[2].Status)
+	assert.Equal(t, "Rye Bread", response.Results[2].Item.Name)
+
+	// The newer change of somebody else is kept
+	assert.Equal(t, bizshoplist.OfflineOpConflict, response.Results[3].Status)
+	assert.False(t, response.Results[3].Item.IsBought)
+	assert.Equal(t, 1, response.Results[3].Item.Version)
+
+	assert.Equal(t, bizshoplist.OfflineOpRejected, response.Results[4].Status)
+	assert.Equal(t, "Item not found.", response.Results[4].Error)
+	assert.Nil(t, response.Results[4].Item)
+
+	var storedItems []db.ShoplistItem
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).Order("id").Find(&storedItems).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(storedItems))
+	assert.Equal(t, "Rye Bread", storedItems[0].ItemName)
+	assert.False(t, storedItems[1].IsBought)
+	assert.True(t, storedItems[2].IsBought)
+
+	// A retried batch does not add the item again
+	w, response = replay(users[0].ID, []map[string]interface{}{addMilk})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, bizshoplist.OfflineOpDuplicate, response.Results[0].Status)
+	assert.Equal(t, milk.ItemID, response.Results[0].ItemID)
+
+	var itemCount int64
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("shop_list_id = ?", testShoplist.ID).Count(&itemCount).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), itemCount)
+
+	// Viewers cannot replay operations
+	w, _ = replay(users[1].ID, []map[string]interface{}{addMilk})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// An empty batch
+	w, _ = replay(users[0].ID, []map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,226 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+const (
+	// purchaseDateLayout is the layout of the from and to dates of the purchase history
+	purchaseDateLayout = "2006-01-02"
+	// groupPurchasesByItem returns how often every item was bought instead of the purchases
+	groupPurchasesByItem = "item"
+)
+
+// GetShoplistPurchaseHistory returns the purchase history of a shoplist
+// @Summary Get shoplist purchase history
+// @Description Returns the items bought in a shoplist, newest first. An entry is recorded every time an item is marked as bought and is kept when the item is removed. Pass the returned next_cursor as cursor to get the next page.
+// @Description With group_by=item the response lists how often every item was bought, most bought first.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param from query string false "First day of purchases, YYYY-MM-DD in UTC"
+// @Param to query string false "Last day of purchases, YYYY-MM-DD in UTC"
+// @Param limit query int false "Number of entries per page, up to 100"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param group_by query string false "Set to item to count the purchases of every item"
+// @Success 200 {object} PurchasePageResponse "Successfully got purchase history"
+// @Success 200 {object} PurchaseCountsResponse "Successfully got purchase counts"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid from, to, limit, cursor or group_by"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/purchases [get]
+func (h *ShoplistHandler) GetShoplistPurchaseHistory(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetShoplistPurchaseHistory: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	h.respondPurchaseHistory(c, "GetShoplistPurchaseHistory",
+		func(query bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.ShoplistPurchase, *bizshoplist.ShoplistError) {
+			return h.shoplistBiz.GetShoplistPurchaseHistory(c, userID, shoplistID, query)
+		},
+		func(query bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.PurchaseCount, *bizshoplist.ShoplistError) {
+			return h.shoplistBiz.GetShoplistPurchaseCounts(c, userID, shoplistID, query)
+		})
+}
+
+// GetUserPurchaseHistory returns the purchase history of the user
+// @Summary Get user purchase history
+// @Description Returns the items the user bought in any shoplist, newest first. Pass the returned next_cursor as cursor to get the next page.
+// @Description With group_by=item the response lists how often every item was bought, most bought first.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param from query string false "First day of purchases, YYYY-MM-DD in UTC"
+// @Param to query string false "Last day of purchases, YYYY-MM-DD in UTC"
+// @Param limit query int false "Number of entries per page, up to 100"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param group_by query string false "Set to item to count the purchases of every item"
+// @Success 200 {object} PurchasePageResponse "Successfully got purchase history"
+// @Success 200 {object} PurchaseCountsResponse "Successfully got purchase counts"
+// @Failure 400 {object} map[string]string "Invalid from, to, limit, cursor or group_by"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/purchases [get]
+func (h *ShoplistHandler) GetUserPurchaseHistory(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetUserPurchaseHistory: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	h.respondPurchaseHistory(c, "GetUserPurchaseHistory",
+		func(query bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.ShoplistPurchase, *bizshoplist.ShoplistError) {
+			return h.shoplistBiz.GetUserPurchaseHistory(c, userID, query)
+		},
+		func(query bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.PurchaseCount, *bizshoplist.ShoplistError) {
+			return h.shoplistBiz.GetUserPurchaseCounts(c, userID, query)
+		})
+}
+
+// respondPurchaseHistory parses the history query and responds with a page of purchases, or with the purchase counts
+// when grouped by item
+func (h *ShoplistHandler) respondPurchaseHistory(c *gin.Context, handlerName string,
+	getPurchases func(bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.ShoplistPurchase, *bizshoplist.ShoplistError),
+	getCounts func(bizshoplist.PurchaseHistoryQuery) ([]bizshoplist.PurchaseCount, *bizshoplist.ShoplistError)) {
+	query, invalidParam := parsePurchaseHistoryQuery(c)
+	if invalidParam != "" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, invalidParam)
+		return
+	}
+
+	if c.Query("group_by") == groupPurchasesByItem {
+		counts, shoplistErr := getCounts(query)
+		if shoplistErr != nil {
+			h.purchaseHistoryError(c, handlerName, shoplistErr)
+			return
+		}
+
+		response := PurchaseCountsResponse{
+			Items: make([]PurchaseCountResponse, 0, len(counts)),
+		}
+		for _, count := range counts {
+			response.Items = append(response.Items, PurchaseCountResponse{
+				ItemName:        count.ItemName,
+				BrandName:       count.BrandName,
+				Count:           count.Count,
+				LastPurchasedAt: count.LastPurchasedAt.Format(time.RFC3339),
+			})
+		}
+		h.responseFactory.CreateOKResponse(c, response)
+		return
+	}
+
+	purchases, shoplistErr := getPurchases(query)
+	if shoplistErr != nil {
+		h.purchaseHistoryError(c, handlerName, shoplistErr)
+		return
+	}
+
+	response := PurchasePageResponse{
+		Purchases: make([]PurchaseResponse, 0, len(purchases)),
+	}
+	for _, purchase := range purchases {
+		response.Purchases = append(response.Purchases, PurchaseResponse{
+			ID:           purchase.ID,
+			ShoplistID:   purchase.ShopListID,
+			ShoplistName: purchase.ShopListName,
+			ItemID:       purchase.ItemID,
+			ItemName:     purchase.ItemName,
+			BrandName:    purchase.BrandName,
+			Quantity:     purchase.Quantity,
+			Unit:         purchase.Unit,
+			Category:     purchase.Category,
+			Buyer: OwnerResponse{
+				ID:       purchase.BuyerID,
+				Nickname: purchase.BuyerNickname,
+			},
+			PurchasedAt: purchase.PurchasedAt.Format(time.RFC3339),
+		})
+	}
+
+	// A full page may be followed by more entries
+	if len(purchases) == query.Limit {
+		response.NextCursor = strconv.Itoa(purchases[len(purchases)-1].ID)
+	}
+
+	h.responseFactory.CreateOKResponse(c, response)
+}
+
+func (h *ShoplistHandler) purchaseHistoryError(c *gin.Context, handlerName string, shoplistErr *bizshoplist.ShoplistError) {
+	switch shoplistErr.ErrCode {
+	case bizshoplist.ShoplistNotFound:
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+	default:
+		logger.Errorf("%s: Failed to get purchase history. Error: %s", handlerName, shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+	}
+}
+
+// parsePurchaseHistoryQuery reads the purchase history filters from the query string and returns the name of the
+// first invalid parameter, if any. The to date is inclusive.
+func parsePurchaseHistoryQuery(c *gin.Context) (bizshoplist.PurchaseHistoryQuery, string) {
+	query := bizshoplist.PurchaseHistoryQuery{
+		Limit: bizshoplist.DefaultPurchaseHistoryPage,
+	}
+	var err error
+
+	if fromParam := c.Query("from"); fromParam != "" {
+		query.From, err = time.Parse(purchaseDateLayout, fromParam)
+		if err != nil {
+			return query, "from"
+		}
+	}
+
+	if toParam := c.Query("to"); toParam != "" {
+		to, err := time.Parse(purchaseDateLayout, toParam)
+		if err != nil || (!query.From.IsZero() && to.Before(query.From)) {
+			return query, "to"
+		}
+		query.To = to.AddDate(0, 0, 1)
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		query.Limit, err = strconv.Atoi(limitParam)
+		if err != nil || query.Limit <= 0 || query.Limit > bizshoplist.MaxPurchaseHistoryPage {
+			return query, "limit"
+		}
+	}
+
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != groupPurchasesByItem {
+		return query, "group_by"
+	}
+
+	// Purchase counts come on a single page
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		query.BeforeID, err = strconv.Atoi(cursorParam)
+		if err != nil || query.BeforeID <= 0 || groupBy != "" {
+			return query, "cursor"
+		}
+	}
+
+	return query, ""
+}
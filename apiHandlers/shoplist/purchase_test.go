@@ -0,0 +1,46 @@
+package apiHandlersshoplist
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePurchaseHistoryQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]string{
+		"from=2025-13-01":                 "from",
+		"to=yesterday":                    "to",
+		"from=2025-03-02&to=2025-03-01":   "to",
+		"limit=0":                         "limit",
+		"limit=101":                       "limit",
+		"cursor=abc":                      "cursor",
+		"group_by=store":                  "group_by",
+		"group_by=item&cursor=10":         "cursor",
+		"from=2025-03-01&limit=1&cursor=": "",
+	}
+	for rawQuery, expected := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/v2/shoplist/purchases?"+rawQuery, nil)
+
+		_, invalidParam := parsePurchaseHistoryQuery(c)
+		assert.Equal(t, expected, invalidParam, rawQuery)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/v2/shoplist/purchases?from=2025-03-01&to=2025-03-01&limit=20&cursor=42", nil)
+
+	query, invalidParam := parsePurchaseHistoryQuery(c)
+	assert.Empty(t, invalidParam)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), query.From)
+	// The to date is inclusive
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), query.To)
+	assert.Equal(t, 20, query.Limit)
+	assert.Equal(t, 42, query.BeforeID)
+}
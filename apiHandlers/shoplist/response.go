@@ -1,10 +1,24 @@
 package apiHandlersshoplist
 
+import "encoding/json"
+
 type ShoplistResponse struct {
-	ID    int            `json:"id"`
-	Name  string         `json:"name"`
-	Owner OwnerResponse  `json:"owner"`
-	Items []ItemResponse `json:"items"`
+	ID      int            `json:"id"`
+	Name    string         `json:"name"`
+	Owner   OwnerResponse  `json:"owner"`
+	Version int            `json:"version"`
+	Items   []ItemResponse `json:"items"`
+	// HasMoreItems is set when only the first items of the shoplist were requested and there are more
+	HasMoreItems bool `json:"has_more_items,omitempty"`
+	// Categories is only populated when the items are requested grouped by category
+	Categories []CategoryResponse `json:"categories,omitempty"`
+	// Degraded is set when the flyers were requested but could not be matched
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type CategoryResponse struct {
+	Category string         `json:"category"`
+	Items    []ItemResponse `json:"items"`
 }
 
 type OwnerResponse struct {
@@ -17,8 +31,14 @@ type ItemResponse struct {
 	Name      string          `json:"name"`
 	BrandName string          `json:"brand_name"`
 	ExtraInfo string          `json:"extra_info"`
+	Quantity  float64         `json:"quantity"`
+	Unit      string          `json:"unit"`
+	Category  string          `json:"category"`
 	IsBought  bool            `json:"is_bought"`
+	Version   int             `json:"version"`
 	Flyer     []FlyerResponse `json:"flyer"`
+	// Recurrence is the rule that brings the item back after it is bought, left out when the item does not recur
+	Recurrence string `json:"recurrence,omitempty"`
 }
 
 type FlyerResponse struct {
@@ -33,3 +53,183 @@ type FlyerResponse struct {
 	PriceText     string `json:"price_text"`
 	PostPriceText string `json:"post_price_text"`
 }
+
+type ActivityPageResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type ActivityResponse struct {
+	ID        int             `json:"id"`
+	Action    string          `json:"action"`
+	Actor     OwnerResponse   `json:"actor"`
+	ItemID    *int            `json:"item_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type ShoplistEventResponse struct {
+	ID         int             `json:"id"`
+	ShoplistID int             `json:"shoplist_id"`
+	Action     string          `json:"action"`
+	ActorID    string          `json:"actor_id"`
+	ItemID     *int            `json:"item_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type TrashResponse struct {
+	Shoplists []TrashedShoplistResponse `json:"shoplists"`
+	Items     []TrashedItemResponse     `json:"items"`
+}
+
+type TrashedShoplistResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+type TrashedItemResponse struct {
+	ID           int    `json:"id"`
+	ShoplistID   int    `json:"shoplist_id"`
+	ShoplistName string `json:"shoplist_name"`
+	Name         string `json:"name"`
+	BrandName    string `json:"brand_name"`
+	DeletedAt    string `json:"deleted_at"`
+}
+
+type SyncResponse struct {
+	Cursor           string                 `json:"cursor"`
+	Reset            bool                   `json:"reset"`
+	Shoplists        []SyncShoplistResponse `json:"shoplists"`
+	DeletedShoplists []int                  `json:"deleted_shoplists"`
+	Items            []SyncItemResponse     `json:"items"`
+	DeletedItems     []SyncItemTombstone    `json:"deleted_items"`
+	Members          []SyncMemberResponse   `json:"members"`
+	DeletedMembers   []SyncMemberTombstone  `json:"deleted_members"`
+}
+
+type SyncShoplistResponse struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Owner      OwnerResponse `json:"owner"`
+	Version    int           `json:"version"`
+	IsTemplate bool          `json:"is_template,omitempty"`
+	UpdatedAt  string        `json:"updated_at"`
+}
+
+type SyncItemResponse struct {
+	ID         int     `json:"id"`
+	ShoplistID int     `json:"shoplist_id"`
+	Name       string  `json:"name"`
+	BrandName  string  `json:"brand_name"`
+	ExtraInfo  string  `json:"extra_info"`
+	Quantity   float64 `json:"quantity"`
+	Unit       string  `json:"unit"`
+	Category   string  `json:"category"`
+	IsBought   bool    `json:"is_bought"`
+	Position   int     `json:"position"`
+	Thumbnail  string  `json:"thumbnail"`
+	Version    int     `json:"version"`
+	Recurrence string  `json:"recurrence,omitempty"`
+	UpdatedAt  string  `json:"updated_at"`
+}
+
+type SyncItemTombstone struct {
+	ID         int `json:"id"`
+	ShoplistID int `json:"shoplist_id"`
+}
+
+type SyncMemberResponse struct {
+	ShoplistID int    `json:"shoplist_id"`
+	ID         string `json:"id"`
+	Nickname   string `json:"nickname"`
+	Role       string `json:"role"`
+}
+
+type SyncMemberTombstone struct {
+	ShoplistID int    `json:"shoplist_id"`
+	ID         string `json:"id"`
+}
+
+type ReplayResponse struct {
+	Results []ReplayResultResponse `json:"results"`
+}
+
+type ReplayResultResponse struct {
+	OpID         string `json:"op_id"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	ItemID       int    `json:"item_id,omitempty"`
+	ClientItemID string `json:"client_item_id,omitempty"`
+	// Item is the item after the operation, null when the item no longer exists
+	Item *SyncItemResponse `json:"item"`
+}
+
+type BulkItemsResponse struct {
+	Results []BulkItemResultResponse `json:"results"`
+}
+
+type BulkItemResultResponse struct {
+	ID int `json:"id,omitempty"`
+	// Item is the item after the change, null for removed items and items that were not changed
+	Item  *SyncItemResponse `json:"item"`
+	Code  string            `json:"code,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+type ShoplistTemplateResponse struct {
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Owner     OwnerResponse `json:"owner"`
+	ItemCount int           `json:"item_count"`
+}
+
+type PurchasePageResponse struct {
+	Purchases  []PurchaseResponse `json:"purchases"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type PurchaseResponse struct {
+	ID           int           `json:"id"`
+	ShoplistID   int           `json:"shoplist_id"`
+	ShoplistName string        `json:"shoplist_name"`
+	ItemID       int           `json:"item_id"`
+	ItemName     string        `json:"item_name"`
+	BrandName    string        `json:"brand_name"`
+	Quantity     float64       `json:"quantity"`
+	Unit         string        `json:"unit"`
+	Category     string        `json:"category"`
+	Buyer        OwnerResponse `json:"buyer"`
+	PurchasedAt  string        `json:"purchased_at"`
+}
+
+type PurchaseCountsResponse struct {
+	Items []PurchaseCountResponse `json:"items"`
+}
+
+type PurchaseCountResponse struct {
+	ItemName        string `json:"item_name"`
+	BrandName       string `json:"brand_name"`
+	Count           int    `json:"count"`
+	LastPurchasedAt string `json:"last_purchased_at"`
+}
+
+type ItemSuggestionResponse struct {
+	ItemName   string `json:"item_name"`
+	BrandName  string `json:"brand_name"`
+	Unit       string `json:"unit"`
+	Category   string `json:"category"`
+	Count      int    `json:"count"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+type ParsedItemResponse struct {
+	ItemName  string  `json:"item_name"`
+	BrandName string  `json:"brand_name"`
+	Quantity  float64 `json:"quantity"`
+	Unit      string  `json:"unit"`
+	Category  string  `json:"category"`
+}
@@ -64,10 +64,14 @@ func (h *ShoplistHandler) CreateShoplist(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Shoplist ID"
 // @Param name body string true "Name of the shoplist"
+// @Param If-Match header string false "ETag of the shoplist, the name is only updated if the shoplist did not change since"
 // @Success 200 {object} gin.H
+// @Header 200 {string} ETag "ETag of the updated shoplist"
 // @Failure 400 {object} map[string]string "Name is required"
+// @Failure 400 {object} map[string]string "Invalid If-Match"
 // @Failure 403 {object} map[string]string "Only the owner can update this shoplist"
 // @Failure 404 {object} map[string]string "Not found"
+// @Failure 409 {object} map[string]string "Shoplist was changed by someone else"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Failure 500 {object} map[string]string "Failed to process shoplist data"
 // @Router /shoplist/{id} [post]
@@ -87,6 +91,12 @@ func (h *ShoplistHandler) UpdateShoplist(c *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "If-Match")
+		return
+	}
+
 	// Parse request body
 	var requestBody struct {
 		Name string `json:"name" binding:"required"`
@@ -96,12 +106,14 @@ func (h *ShoplistHandler) UpdateShoplist(c *gin.Context) {
 		return
 	}
 
-	shoplistErr := h.shoplistBiz.UpdateShoplist(c, userID, shoplistID, requestBody.Name)
+	version, shoplistErr := h.shoplistBiz.UpdateShoplist(c, userID, shoplistID, requestBody.Name, expectedVersion)
 	if shoplistErr != nil {
 		if shoplistErr.ErrCode == bizshoplist.ShoplistNotFound {
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		} else if shoplistErr.ErrCode == bizshoplist.ShoplistNotOwned {
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		} else if shoplistErr.ErrCode == bizshoplist.ShoplistVersionConflict {
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistVersionConflict)
 		} else {
 			logger.Errorf("UpdateShoplist: Failed to update shoplist. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -109,16 +121,85 @@ func (h *ShoplistHandler) UpdateShoplist(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", formatETag(version))
+	h.responseFactory.CreateOKResponse(c, nil)
+}
+
+// DeleteShoplist deletes a shoplist for all of its members
+// @Summary Delete a shoplist
+// @Description Deletes a shoplist together with its members, items and share code. Only the owner can delete a shoplist. The shoplist can be restored from the owner's trash until it is purged.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param If-Match header string false "ETag of the shoplist, the shoplist is only deleted if it did not change since"
+// @Success 200 {object} gin.H "Successfully deleted shoplist"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid If-Match"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "Only the owner can delete this shoplist"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 409 {object} map[string]string "Shoplist was changed by someone else"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id} [delete]
+func (h *ShoplistHandler) DeleteShoplist(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("DeleteShoplist: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "If-Match")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.DeleteShoplist(c, userID, shoplistID, expectedVersion)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistVersionConflict:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistVersionConflict)
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		default:
+			logger.Errorf("DeleteShoplist: Failed to delete shoplist. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
 	h.responseFactory.CreateOKResponse(c, nil)
 }
 
 // GetAllShoplistItems retrieves all shoplist items for a user
 // @Summary Get all shoplist items for a user
-// @Description Retrieves all shoplist items for a user
+// @Description Retrieves the shoplists of a user with their items, ordered by ID. Without a limit all shoplists are returned.
+// @Description With a limit, next_cursor is returned when there are more shoplists. With an item_limit, has_more_items is set on shoplists with more items.
 // @Tags shoplist
 // @Accept json
 // @Produce json
+// @Param limit query int false "Number of shoplists per page, up to 50"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param item_limit query int false "Number of items returned per shoplist"
+// @Param ownership query string false "owned for the shoplists of the user, shared for the shoplists shared with the user"
+// @Param has_unbought query bool false "Only return shoplists with items that are not bought"
+// @Param include query string false "Set to flyers to match the items with flyers. When matching fails the shoplists are returned with degraded set."
 // @Success 200 {object} map[string]interface{} "Successfully retrieved shoplist items"
+// @Failure 400 {object} map[string]string "Invalid limit, cursor, item_limit, ownership, has_unbought or include"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Failure 500 {object} map[string]string "Failed to fetch shoplist items"
 // @Router /shoplist/items [get]
@@ -130,24 +211,32 @@ func (h *ShoplistHandler) GetAllShoplistAndItemsForUser(c *gin.Context) {
 		return
 	}
 
-	shoplists, err := h.shoplistBiz.GetAllShoplistAndItemsForUser(c.Request.Context(), userID)
+	query, param := parseShoplistQuery(c)
+	if param != "" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, param)
+		return
+	}
+
+	withFlyers, ok := parseIncludeFlyers(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "include")
+		return
+	}
+
+	page, err := h.shoplistBiz.GetShoplistPageForUser(c.Request.Context(), userID, query)
 	if err != nil {
 		logger.Errorf("GetAllShoplistItems: Failed to get shoplist items. Error: %s", err.Error())
 		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
 		return
 	}
+	shoplists := page.Shoplists
 
 	shoplistItems := make([]bizmodels.ShoplistItem, 0)
 	for _, shoplist := range shoplists {
 		shoplistItems = append(shoplistItems, shoplist.Items...)
 	}
 
-	flyers, matchErr := h.matchBiz.MatchShoplistItemsWithFlyer(c.Request.Context(), shoplistItems)
-	if matchErr != nil {
-		logger.Errorf("Failed to match shoplist items with flyers: %v", matchErr)
-		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
-		return
-	}
+	flyers, degraded := h.matchFlyers(c, "GetAllShoplistItems", shoplistItems, withFlyers)
 
 	// Transform the response to match the desired format
 	response := make([]ShoplistResponse, 0)
@@ -159,47 +248,100 @@ func (h *ShoplistHandler) GetAllShoplistAndItemsForUser(c *gin.Context) {
 				ID:       shoplist.OwnerID,
 				Nickname: shoplist.OwnerNickname,
 			},
-			Items: make([]ItemResponse, 0),
+			Version:      shoplist.Version,
+			Items:        make([]ItemResponse, 0),
+			HasMoreItems: shoplist.HasMoreItems,
 		}
 
 		// Add items for this shoplist
 		for _, item := range shoplist.Items {
-			flyerResp := make([]FlyerResponse, 0)
-
-			flyersForItem, exists := flyers[item.ID]
-			if exists {
-				for _, flyer := range flyersForItem {
-					flyerResp = append(flyerResp, FlyerResponse{
-						Store:         flyer.Store,
-						Brand:         flyer.Brand,
-						StartDate:     flyer.StartDateTime,
-						EndDate:       flyer.EndDateTime,
-						ProductName:   flyer.ProductName,
-						Description:   flyer.Description,
-						OriginalPrice: flyer.OriginalPrice,
-						PrePriceText:  flyer.PrePriceText,
-						PriceText:     flyer.PriceText,
-						PostPriceText: flyer.PostPriceText,
-					})
-				}
-			}
-
 			shoplistResp.Items = append(shoplistResp.Items, ItemResponse{
-				ID:        item.ID,
-				Name:      item.ItemName,
-				BrandName: item.BrandName,
-				ExtraInfo: item.ExtraInfo,
-				IsBought:  item.IsBought,
-				Flyer:     flyerResp,
+				ID:         item.ID,
+				Name:       item.ItemName,
+				BrandName:  item.BrandName,
+				ExtraInfo:  item.ExtraInfo,
+				Quantity:   item.Quantity,
+				Unit:       item.Unit,
+				Category:   item.Category,
+				IsBought:   item.IsBought,
+				Version:    item.Version,
+				Flyer:      flyerResponses(flyers[item.ID]),
+				Recurrence: item.Recurrence,
 			})
 		}
 
 		response = append(response, shoplistResp)
 	}
 
-	h.responseFactory.CreateOKResponse(c, map[string]interface{}{"shoplists": response})
+	responseBody := map[string]interface{}{"shoplists": response}
+	if page.NextCursor != "" {
+		responseBody["next_cursor"] = page.NextCursor
+	}
+	if degraded {
+		responseBody["degraded"] = true
+	}
+
+	h.responseFactory.CreateOKResponse(c, responseBody)
+}
+
+// parseShoplistQuery reads the paging and filters of the shoplist listing. It returns the name of the first invalid
+// parameter.
+func parseShoplistQuery(c *gin.Context) (bizshoplist.ShoplistQuery, string) {
+	var query bizshoplist.ShoplistQuery
+	var err error
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		query.Limit, err = strconv.Atoi(limitParam)
+		if err != nil || query.Limit <= 0 || query.Limit > bizshoplist.MaxShoplistPage {
+			return query, "limit"
+		}
+	}
+
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		query.AfterID, err = bizshoplist.DecodeShoplistCursor(cursorParam)
+		if err != nil {
+			return query, "cursor"
+		}
+	}
+
+	if itemLimitParam := c.Query("item_limit"); itemLimitParam != "" {
+		query.ItemLimit, err = strconv.Atoi(itemLimitParam)
+		if err != nil || query.ItemLimit <= 0 {
+			return query, "item_limit"
+		}
+	}
+
+	query.Ownership = c.Query("ownership")
+	if !bizshoplist.IsValidShoplistOwnership(query.Ownership) {
+		return query, "ownership"
+	}
+
+	if hasUnboughtParam := c.Query("has_unbought"); hasUnboughtParam != "" {
+		query.HasUnbought, err = strconv.ParseBool(hasUnboughtParam)
+		if err != nil {
+			return query, "has_unbought"
+		}
+	}
+
+	return query, ""
 }
 
+// GetShoplistAndItemsForUserByShoplistID retrieves a shoplist and its items
+// @Summary Get a shoplist and its items
+// @Description Retrieves a shoplist and its items for a member. With group_by=category the items are also returned grouped by category in a stable order.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param group_by query string false "Set to category to group the items by category"
+// @Param include query string false "Set to flyers to match the items with flyers. When matching fails the shoplist is returned with degraded set."
+// @Success 200 {object} ShoplistResponse "Successfully retrieved shoplist"
+// @Header 200 {string} ETag "ETag of the shoplist, to send as If-Match when updating or deleting it"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid group_by or include"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Router /shoplist/{id} [get]
 func (h *ShoplistHandler) GetShoplistAndItemsForUserByShoplistID(c *gin.Context) {
 	userID := c.GetString("userID")
 	if userID == "" {
@@ -214,6 +356,18 @@ func (h *ShoplistHandler) GetShoplistAndItemsForUserByShoplistID(c *gin.Context)
 		return
 	}
 
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "category" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "group_by")
+		return
+	}
+
+	withFlyers, ok := parseIncludeFlyers(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "include")
+		return
+	}
+
 	shoplist, err := h.shoplistBiz.GetShoplistAndItems(c.Request.Context(), userID, shoplistID)
 	if err != nil {
 		logger.Errorf("GetShoplistAndItemsForUserByShoplistID: Failed to get shoplist. Error: %s", err.Error())
@@ -229,49 +383,51 @@ func (h *ShoplistHandler) GetShoplistAndItemsForUserByShoplistID(c *gin.Context)
 			ID:       shoplist.OwnerID,
 			Nickname: shoplist.OwnerNickname,
 		},
-		Items: make([]ItemResponse, 0),
+		Version: shoplist.Version,
+		Items:   make([]ItemResponse, 0),
 	}
 
 	if len(shoplist.Items) > 0 {
-		flyers, matchErr := h.matchBiz.MatchShoplistItemsWithFlyer(c.Request.Context(), shoplist.Items)
-		if matchErr != nil {
-			logger.Errorf("GetShoplistAndItemsForUserByShoplistID: Failed to match shoplist items with flyers. Error: %s", matchErr.Error())
-			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
-			return
-		}
+		var flyers map[int][]*bizmodels.Flyer
+		flyers, shoplistResp.Degraded = h.matchFlyers(c, "GetShoplistAndItemsForUserByShoplistID", shoplist.Items, withFlyers)
 
 		// Add items for this shoplist
 		for _, item := range shoplist.Items {
-			flyerResp := make([]FlyerResponse, 0)
-
-			flyersForItem, exists := flyers[item.ID]
-			if exists {
-				for _, flyer := range flyersForItem {
-					flyerResp = append(flyerResp, FlyerResponse{
-						Store:         flyer.Store,
-						Brand:         flyer.Brand,
-						StartDate:     flyer.StartDateTime,
-						EndDate:       flyer.EndDateTime,
-						ProductName:   flyer.ProductName,
-						Description:   flyer.Description,
-						OriginalPrice: flyer.OriginalPrice,
-						PrePriceText:  flyer.PrePriceText,
-						PriceText:     flyer.PriceText,
-						PostPriceText: flyer.PostPriceText,
-					})
-				}
-			}
-
 			shoplistResp.Items = append(shoplistResp.Items, ItemResponse{
-				ID:        item.ID,
-				Name:      item.ItemName,
-				BrandName: item.BrandName,
-				ExtraInfo: item.ExtraInfo,
-				IsBought:  item.IsBought,
-				Flyer:     flyerResp,
+				ID:         item.ID,
+				Name:       item.ItemName,
+				BrandName:  item.BrandName,
+				ExtraInfo:  item.ExtraInfo,
+				Quantity:   item.Quantity,
+				Unit:       item.Unit,
+				Category:   item.Category,
+				IsBought:   item.IsBought,
+				Version:    item.Version,
+				Flyer:      flyerResponses(flyers[item.ID]),
+				Recurrence: item.Recurrence,
 			})
 		}
+
+		if groupBy == "category" {
+			itemRespByID := make(map[int]ItemResponse)
+			for _, itemResp := range shoplistResp.Items {
+				itemRespByID[itemResp.ID] = itemResp
+			}
+
+			shoplistResp.Categories = make([]CategoryResponse, 0)
+			for _, group := range bizshoplist.GroupItemsByCategory(shoplist.Items) {
+				categoryResp := CategoryResponse{
+					Category: group.Category,
+					Items:    make([]ItemResponse, 0, len(group.Items)),
+				}
+				for _, item := range group.Items {
+					categoryResp.Items = append(categoryResp.Items, itemRespByID[item.ID])
+				}
+				shoplistResp.Categories = append(shoplistResp.Categories, categoryResp)
+			}
+		}
 	}
 
+	c.Header("ETag", formatETag(shoplist.Version))
 	h.responseFactory.CreateOKResponse(c, shoplistResp)
 }
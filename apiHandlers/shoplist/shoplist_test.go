@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -576,8 +577,9 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 		expectedBody := map[string]interface{}{
 			"shoplists": []interface{}{
 				map[string]interface{}{
-					"id":   float64(1),
-					"name": "Shoplist 1",
+					"id":      float64(1),
+					"name":    "Shoplist 1",
+					"version": float64(1),
 					"owner": map[string]interface{}{
 						"id":       "owner-123",
 						"nickname": "Owner",
@@ -588,7 +590,11 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 							"name":       "Test Item 1",
 							"brand_name": "Test Brand 1",
 							"extra_info": "Test Info 1",
+							"quantity":   float64(0),
+							"unit":       "",
+							"category":   "other",
 							"is_bought":  false,
+							"version":    float64(1),
 							"flyer":      []interface{}{},
 						},
 						map[string]interface{}{
@@ -596,7 +602,11 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 							"name":       "Test Item 2",
 							"brand_name": "Test Brand 2",
 							"extra_info": "Test Info 2",
+							"quantity":   float64(0),
+							"unit":       "",
+							"category":   "other",
 							"is_bought":  true,
+							"version":    float64(1),
 							"flyer":      []interface{}{},
 						},
 					},
@@ -630,8 +640,9 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 		expectedBody := map[string]interface{}{
 			"shoplists": []interface{}{
 				map[string]interface{}{
-					"id":   float64(1),
-					"name": "Shoplist 1",
+					"id":      float64(1),
+					"name":    "Shoplist 1",
+					"version": float64(1),
 					"owner": map[string]interface{}{
 						"id":       "owner-123",
 						"nickname": "Owner",
@@ -642,7 +653,11 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 							"name":       "Test Item 1",
 							"brand_name": "Test Brand 1",
 							"extra_info": "Test Info 1",
+							"quantity":   float64(0),
+							"unit":       "",
+							"category":   "other",
 							"is_bought":  false,
+							"version":    float64(1),
 							"flyer":      []interface{}{},
 						},
 						map[string]interface{}{
@@ -650,14 +665,19 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 							"name":       "Test Item 2",
 							"brand_name": "Test Brand 2",
 							"extra_info": "Test Info 2",
+							"quantity":   float64(0),
+							"unit":       "",
+							"category":   "other",
 							"is_bought":  true,
+							"version":    float64(1),
 							"flyer":      []interface{}{},
 						},
 					},
 				},
 				map[string]interface{}{
-					"id":   float64(2),
-					"name": "Shoplist 2",
+					"id":      float64(2),
+					"name":    "Shoplist 2",
+					"version": float64(1),
 					"owner": map[string]interface{}{
 						"id":       "member-123",
 						"nickname": "Member",
@@ -668,7 +688,11 @@ func TestGetAllShoplistAndItemsForUser(t *testing.T) {
 							"name":       "Test Item 3",
 							"brand_name": "Test Brand 3",
 							"extra_info": "Test Info 3",
+							"quantity":   float64(0),
+							"unit":       "",
+							"category":   "other",
 							"is_bought":  false,
+							"version":    float64(1),
 							"flyer":      []interface{}{},
 						},
 					},
@@ -801,8 +825,9 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 		assert.NoError(t, err)
 
 		expectedBody := map[string]interface{}{
-			"id":   float64(1),
-			"name": "Test Shoplist",
+			"id":      float64(1),
+			"name":    "Test Shoplist",
+			"version": float64(1),
 			"owner": map[string]interface{}{
 				"id":       "owner-123",
 				"nickname": "Owner",
@@ -813,7 +838,11 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 					"name":       "Test Item 1",
 					"brand_name": "Test Brand 1",
 					"extra_info": "Test Info 1",
+					"quantity":   float64(0),
+					"unit":       "",
+					"category":   "other",
 					"is_bought":  false,
+					"version":    float64(1),
 					"flyer":      []interface{}{},
 				},
 				map[string]interface{}{
@@ -821,7 +850,11 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 					"name":       "Test Item 2",
 					"brand_name": "Test Brand 2",
 					"extra_info": "Test Info 2",
+					"quantity":   float64(0),
+					"unit":       "",
+					"category":   "other",
 					"is_bought":  true,
+					"version":    float64(1),
 					"flyer":      []interface{}{},
 				},
 			},
@@ -852,8 +885,9 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 		assert.NoError(t, err)
 
 		expectedBody := map[string]interface{}{
-			"id":   float64(1),
-			"name": "Test Shoplist",
+			"id":      float64(1),
+			"name":    "Test Shoplist",
+			"version": float64(1),
 			"owner": map[string]interface{}{
 				"id":       "owner-123",
 				"nickname": "Owner",
@@ -864,7 +898,11 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 					"name":       "Test Item 1",
 					"brand_name": "Test Brand 1",
 					"extra_info": "Test Info 1",
+					"quantity":   float64(0),
+					"unit":       "",
+					"category":   "other",
 					"is_bought":  false,
+					"version":    float64(1),
 					"flyer":      []interface{}{},
 				},
 				map[string]interface{}{
@@ -872,7 +910,11 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 					"name":       "Test Item 2",
 					"brand_name": "Test Brand 2",
 					"extra_info": "Test Info 2",
+					"quantity":   float64(0),
+					"unit":       "",
+					"category":   "other",
 					"is_bought":  true,
+					"version":    float64(1),
 					"flyer":      []interface{}{},
 				},
 			},
@@ -955,8 +997,9 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 		assert.NoError(t, err)
 
 		expectedBody := map[string]interface{}{
-			"id":   float64(2),
-			"name": "Empty Shoplist",
+			"id":      float64(2),
+			"name":    "Empty Shoplist",
+			"version": float64(1),
 			"owner": map[string]interface{}{
 				"id":       "owner-123",
 				"nickname": "Owner",
@@ -966,3 +1009,450 @@ func TestGetShoplistAndItemsForUserByShoplistID(t *testing.T) {
 		assert.Equal(t, expectedBody, response)
 	}
 }
+
+func TestGetShoplistAndItemsForUserByShoplistIDGroupedByCategory(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := dbmodel.User{
+		ID:         "owner-123",
+		Nickname:   "Owner",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := dbmodel.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := dbmodel.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Add items in an order different from the category order
+	items := []dbmodel.ShoplistItem{
+		{ID: 1, ShopListID: testShoplist.ID, ItemName: "Milk", Category: "dairy"},
+		{ID: 2, ShopListID: testShoplist.ID, ItemName: "Apples", Category: "produce"},
+		{ID: 3, ShopListID: testShoplist.ID, ItemName: "Cheese", Category: "dairy"},
+	}
+	for _, item := range items {
+		err = testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	// Create request
+	req, _ := http.NewRequest("GET", "/shoplist/1?group_by=category", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.GetShoplistAndItemsForUserByShoplistID(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response ShoplistResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(response.Items))
+	assert.Equal(t, 2, len(response.Categories))
+	assert.Equal(t, "produce", response.Categories[0].Category)
+	assert.Equal(t, 1, len(response.Categories[0].Items))
+	assert.Equal(t, 2, response.Categories[0].Items[0].ID)
+	assert.Equal(t, "dairy", response.Categories[1].Category)
+	assert.Equal(t, 2, len(response.Categories[1].Items))
+	assert.Equal(t, 1, response.Categories[1].Items[0].ID)
+	assert.Equal(t, 3, response.Categories[1].Items[1].ID)
+
+	// Invalid group_by value
+	req, _ = http.NewRequest("GET", "/shoplist/1?group_by=brand", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.GetShoplistAndItemsForUserByShoplistID(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteShoplistOwner(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	owner := dbmodel.User{
+		ID:         "owner-123",
+		Nickname:   "Owner",
+		PostalCode: "238801",
+	}
+	member := dbmodel.User{
+		ID:         "member-123",
+		Nickname:   "Member",
+		PostalCode: "238802",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&member).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := dbmodel.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner and member to shoplist
+	members := []dbmodel.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: owner.ID},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: member.ID},
+	}
+	for _, m := range members {
+		err = testConn.GetDB().Create(&m).Error
+		assert.NoError(t, err)
+	}
+
+	// Add item and share code
+	item := dbmodel.ShoplistItem{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		ItemName:   "Test Item",
+	}
+	err = testConn.GetDB().Create(&item).Error
+	assert.NoError(t, err)
+	shareCode := dbmodel.ShoplistShareCode{
+		ShopListID: testShoplist.ID,
+		Code:       "ABC123",
+		Expiry:     time.Now().Add(24 * time.Hour),
+	}
+	err = testConn.GetDB().Create(&shareCode).Error
+	assert.NoError(t, err)
+
+	// Create request
+	req, _ := http.NewRequest("DELETE", "/shoplist/1", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.DeleteShoplist(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Verify database
+	var count int64
+	err = testConn.GetDB().Model(&dbmodel.Shoplist{}).Where("id = ?", testShoplist.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count, "Shoplist should be deleted")
+
+	err = testConn.GetDB().Model(&dbmodel.ShoplistMember{}).Where("shop_list_id = ?", testShoplist.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count, "Members should be removed")
+
+	err = testConn.GetDB().Model(&dbmodel.ShoplistItem{}).Where("shop_list_id = ?", testShoplist.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count, "Items should be removed")
+
+	err = testConn.GetDB().Model(&dbmodel.ShoplistShareCode{}).Where("shop_list_id = ?", testShoplist.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count, "Share code should be removed")
+}
+
+func TestDeleteShoplistMember(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	owner := dbmodel.User{
+		ID:         "owner-123",
+		Nickname:   "Owner",
+		PostalCode: "238801",
+	}
+	member := dbmodel.User{
+		ID:         "member-123",
+		Nickname:   "Member",
+		PostalCode: "238802",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&member).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := dbmodel.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner and member to shoplist
+	members := []dbmodel.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: owner.ID},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: member.ID},
+	}
+	for _, m := range members {
+		err = testConn.GetDB().Create(&m).Error
+		assert.NoError(t, err)
+	}
+
+	// Create request
+	req, _ := http.NewRequest("DELETE", "/shoplist/1", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", member.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.DeleteShoplist(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00002",
+		"error": "Only the owner can perform this action.",
+	}, response)
+
+	// Verify shoplist still exists
+	var shoplist dbmodel.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+}
+
+func TestUpdateAndDeleteShoplistIfMatch(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := dbmodel.User{
+		ID:         "owner-123",
+		Nickname:   "Owner",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := dbmodel.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := dbmodel.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+		Role:       "owner",
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// The shoplist is returned with its ETag
+	req, _ := http.NewRequest("GET", "/shoplist/1", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.GetShoplistAndItemsForUserByShoplistID(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	rename := func(ifMatch string, name string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"name": name})
+		req, _ := http.NewRequest("POST", "/shoplist/1", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", owner.ID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.UpdateShoplist(c)
+		return w
+	}
+
+	w = rename(etag, "Renamed Shoplist")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// A rename based on the old version is rejected
+	w = rename(etag, "Stale Shoplist")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var shoplist dbmodel.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed Shoplist", shoplist.Name)
+
+	// Deleting with the old version is rejected as well
+	req, _ = http.NewRequest("DELETE", "/shoplist/1", nil)
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.DeleteShoplist(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req.Header.Set("If-Match", `"2"`)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.DeleteShoplist(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetAllShoplistAndItemsForUserPaged(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	owner := dbmodel.User{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"}
+	member := dbmodel.User{ID: "member-123", Nickname: "Member", PostalCode: "238802"}
+	assert.NoError(t, testConn.GetDB().Create(&owner).Error)
+	assert.NoError(t, testConn.GetDB().Create(&member).Error)
+
+	// The owner owns shoplists 1 and 2 and is a member of shoplist 3
+	for _, shoplist := range []dbmodel.Shoplist{
+		{ID: 1, OwnerID: owner.ID, Name: "Shoplist 1"},
+		{ID: 2, OwnerID: owner.ID, Name: "Shoplist 2"},
+		{ID: 3, OwnerID: member.ID, Name: "Shoplist 3"},
+	} {
+		assert.NoError(t, testConn.GetDB().Create(&shoplist).Error)
+		assert.NoError(t, testConn.GetDB().Create(&dbmodel.ShoplistMember{ShopListID: shoplist.ID, MemberID: owner.ID}).Error)
+	}
+
+	// Shoplist 1 has three items, shoplist 2 only bought items and shoplist 3 one item
+	for i, item := range []dbmodel.ShoplistItem{
+		{ShopListID: 1, ItemName: "Item 1"},
+		{ShopListID: 1, ItemName: "Item 2"},
+		{ShopListID: 1, ItemName: "Item 3"},
+		{ShopListID: 2, ItemName: "Item 4", IsBought: true},
+		{ShopListID: 3, ItemName: "Item 5"},
+	} {
+		item.ID = i + 1
+		item.Position = i + 1
+		assert.NoError(t, testConn.GetDB().Create(&item).Error)
+	}
+
+	getShoplists := func(query string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/shoplist?"+query, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", owner.ID)
+
+		shoplistHandler.GetAllShoplistAndItemsForUser(c)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	shoplistIDs := func(response map[string]interface{}) []float64 {
+		ids := make([]float64, 0)
+		for _, shoplist := range response["shoplists"].([]interface{}) {
+			ids = append(ids, shoplist.(map[string]interface{})["id"].(float64))
+		}
+		return ids
+	}
+
+	// Test case 1: Pages of two shoplists with a cursor
+	{
+		code, response := getShoplists("limit=2")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []float64{1, 2}, shoplistIDs(response))
+		cursor, ok := response["next_cursor"].(string)
+		assert.True(t, ok)
+
+		code, response = getShoplists("limit=2&cursor=" + cursor)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []float64{3}, shoplistIDs(response))
+		_, ok = response["next_cursor"]
+		assert.False(t, ok)
+	}
+
+	// Test case 2: Item limit
+	{
+		code, response := getShoplists("limit=1&item_limit=2")
+		assert.Equal(t, http.StatusOK, code)
+		shoplist := response["shoplists"].([]interface{})[0].(map[string]interface{})
+		assert.Len(t, shoplist["items"], 2)
+		assert.Equal(t, true, shoplist["has_more_items"])
+
+		code, response = getShoplists("item_limit=3")
+		assert.Equal(t, http.StatusOK, code)
+		for _, shoplist := range response["shoplists"].([]interface{}) {
+			_, ok := shoplist.(map[string]interface{})["has_more_items"]
+			assert.False(t, ok)
+		}
+	}
+
+	// Test case 3: Ownership and unbought filters
+	{
+		code, response := getShoplists("ownership=owned")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []float64{1, 2}, shoplistIDs(response))
+
+		code, response = getShoplists("ownership=shared")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []float64{3}, shoplistIDs(response))
+
+		code, response = getShoplists("ownership=owned&has_unbought=true")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []float64{1}, shoplistIDs(response))
+	}
+
+	// Test case 4: Invalid parameters
+	{
+		for _, query := range []string{"limit=0", "limit=51", "cursor=abc", "item_limit=-1", "ownership=mine", "has_unbought=maybe"} {
+			code, _ := getShoplists(query)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+		}
+	}
+}
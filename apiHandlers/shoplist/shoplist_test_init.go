@@ -21,7 +21,7 @@ func setUpShoplistTestEnv(t *testing.T) (*ShoplistHandler, *db.MySQLConnectionPo
 	testDBConn := testutil.SetupTestEnv(t)
 	esc, err := elasticsearch.NewElasticsearchClient(elasticsearchHost, elasticsearchPort)
 	require.NoError(t, err)
-	shoplistBiz := bizshoplist.InitializeShoplistBiz(*testDBConn)
+	shoplistBiz := bizshoplist.InitializeShoplistBiz(*testDBConn, bizshoplist.NewInProcessShoplistEventBroker(0), 0)
 	matchBiz := bizmatch.NewMatchShoplistItemsWithFlyerBiz(esc, testDBConn)
 	shoplistHandler := InitializeShoplistHandler(*testDBConn, shoplistBiz, matchBiz, apiHandlers.ResponseFactory{})
 	return shoplistHandler, testDBConn
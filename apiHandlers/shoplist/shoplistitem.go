@@ -12,6 +12,7 @@ import (
 // AddItemToShopList adds a new item to a shoplist
 // @Summary Add a new item to a shoplist
 // @Description Adds a new item to a specific shoplist. The user must be a member of the shoplist to add items.
+// @Description With parse the quantity, unit and brand are read from the item name, as in "2 lbs Maple Leaf chicken breast". The brand, quantity and unit sent in the request take precedence.
 // @Tags shoplist
 // @Accept json
 // @Produce json
@@ -21,10 +22,16 @@ import (
 //	    ItemName  string `json:"item_name" binding:"required"`
 //	    BrandName string `json:"brand_name"`
 //	    ExtraInfo string `json:"extra_info"`
+//	    Quantity  float64 `json:"quantity"`
+//	    Unit      string `json:"unit"`
+//	    Category  string `json:"category"`
+//	    Parse     bool `json:"parse"`
 //	} true "Item details"
 //
 // @Success 201 {object} map[string]interface{} "Successfully added item"
 // @Failure 400 {object} map[string]string "Item name is required"
+// @Failure 400 {object} map[string]string "Invalid quantity or unit"
+// @Failure 400 {object} map[string]string "Invalid category"
 // @Failure 404 {object} map[string]string "Not found"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Router /shoplist/{id}/items [put]
@@ -46,23 +53,47 @@ func (h *ShoplistHandler) AddItemToShopList(c *gin.Context) {
 
 	// Parse request body
 	var requestBody struct {
-		ItemName  string `json:"item_name" binding:"required"`
-		BrandName string `json:"brand_name"`
-		ExtraInfo string `json:"extra_info"`
-		Thumbnail string `json:"thumbnail"`
+		ItemName  string  `json:"item_name" binding:"required"`
+		BrandName string  `json:"brand_name"`
+		ExtraInfo string  `json:"extra_info"`
+		Thumbnail string  `json:"thumbnail"`
+		Quantity  float64 `json:"quantity"`
+		Unit      string  `json:"unit"`
+		Category  string  `json:"category"`
+		Parse     bool    `json:"parse"`
 	}
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "item_name")
 		return
 	}
 
-	newItem, shoplistErr := h.shoplistBiz.AddItemToShopList(c, userID, shoplistID, requestBody.ItemName, requestBody.BrandName, requestBody.ExtraInfo, requestBody.Thumbnail)
+	if requestBody.Parse {
+		parsed := bizshoplist.ParseItemText(requestBody.ItemName)
+		requestBody.ItemName = parsed.ItemName
+		if requestBody.BrandName == "" {
+			requestBody.BrandName = parsed.BrandName
+		}
+		if requestBody.Quantity == 0 && requestBody.Unit == "" {
+			requestBody.Quantity = parsed.Quantity
+			requestBody.Unit = parsed.Unit
+		}
+	}
+
+	newItem, shoplistErr := h.shoplistBiz.AddItemToShopList(c, userID, shoplistID, requestBody.ItemName, requestBody.BrandName, requestBody.ExtraInfo, requestBody.Thumbnail, requestBody.Quantity, requestBody.Unit, requestBody.Category)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
 		case bizshoplist.ShoplistItemNameEmpty:
 			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "item_name")
+		case bizshoplist.ShoplistItemInvalidUnit:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemUnit)
+		case bizshoplist.ShoplistItemInvalidQuantity:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemQuantity)
+		case bizshoplist.ShoplistItemInvalidCategory:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemCategory)
 		case bizshoplist.ShoplistFailedToCreate:
 			logger.Errorf("AddItemToShopList: Failed to add item. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -79,10 +110,15 @@ func (h *ShoplistHandler) AddItemToShopList(c *gin.Context) {
 		"item_name":  newItem.ItemName,
 		"brand_name": newItem.BrandName,
 		"extra_info": newItem.ExtraInfo,
+		"quantity":   newItem.Quantity,
+		"unit":       newItem.Unit,
+		"category":   newItem.Category,
 		"is_bought":  newItem.IsBought,
 		"thumbnail":  newItem.Thumbnail,
+		"version":    newItem.Version,
 	}
 
+	c.Header("ETag", formatETag(newItem.Version))
 	h.responseFactory.CreateCreatedResponse(c, respData)
 }
 
@@ -94,11 +130,14 @@ func (h *ShoplistHandler) AddItemToShopList(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Shoplist ID"
 // @Param itemId path int true "Item ID"
+// @Param If-Match header string false "ETag of the item, the item is only removed if it did not change since"
 // @Success 200 {object} gin.H "Successfully removed item"
 // @Failure 400 {object} map[string]string "Invalid shoplist ID"
 // @Failure 400 {object} map[string]string "Invalid item ID"
+// @Failure 400 {object} map[string]string "Invalid If-Match"
 // @Failure 404 {object} map[string]string "Not found"
 // @Failure 404 {object} map[string]string "Item not found"
+// @Failure 409 {object} map[string]string "Item was changed by someone else"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Router /shoplist/{id}/items/{itemId} [delete]
 func (h *ShoplistHandler) RemoveItemFromShopList(c *gin.Context) {
@@ -123,11 +162,21 @@ func (h *ShoplistHandler) RemoveItemFromShopList(c *gin.Context) {
 		return
 	}
 
-	shoplistErr := h.shoplistBiz.RemoveItemFromShopList(c, userID, shoplistID, itemID)
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "If-Match")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.RemoveItemFromShopList(c, userID, shoplistID, itemID, expectedVersion)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistVersionConflict:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistVersionConflict)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
 		case bizshoplist.ShoplistNotMember:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		case bizshoplist.ShoplistItemNotFound:
@@ -147,7 +196,8 @@ func (h *ShoplistHandler) RemoveItemFromShopList(c *gin.Context) {
 
 // UpdateShoplistItem updates the bought status of an item
 // @Summary Update an item in a shoplist
-// @Description Updates the details of a specific item in a shoplist. At least one of the fields (item_name, brand_name, extra_info, is_bought) must be present in the request body. The user must be a member of the shoplist to update items.
+// @Description Updates the details of a specific item in a shoplist. At least one of the fields (item_name, brand_name, extra_info, quantity, unit, category, is_bought, recurrence) must be present in the request body. The user must be a member of the shoplist to update items.
+// @Description A recurrence of days:N or weekly:<weekday> brings the item back as not bought once it is due after being marked as bought, an empty recurrence stops the item from recurring.
 // @Tags shoplist
 // @Accept json
 // @Produce json
@@ -158,15 +208,26 @@ func (h *ShoplistHandler) RemoveItemFromShopList(c *gin.Context) {
 //	    ItemName  *string `json:"item_name"`
 //	    BrandName *string `json:"brand_name"`
 //	    ExtraInfo *string `json:"extra_info"`
+//	    Quantity  *float64 `json:"quantity"`
+//	    Unit      *string `json:"unit"`
+//	    Category  *string `json:"category"`
 //	    IsBought  *bool   `json:"is_bought"`
+//	    Recurrence *string `json:"recurrence"`
 //	} true "Item details"
 //
+// @Param If-Match header string false "ETag of the item, the item is only updated if it did not change since"
 // @Success 200 {object} map[string]interface{} "Successfully updated item"
 // @Failure 400 {object} map[string]string "Invalid shoplist ID"
 // @Failure 400 {object} map[string]string "Invalid item ID"
+// @Failure 400 {object} map[string]string "Invalid If-Match"
 // @Failure 400 {object} map[string]string "At least one field must be present in the request body"
+// @Failure 400 {object} map[string]string "Item name must not be empty"
+// @Failure 400 {object} map[string]string "Invalid quantity or unit"
+// @Failure 400 {object} map[string]string "Invalid category"
+// @Failure 400 {object} map[string]string "Invalid recurrence"
 // @Failure 404 {object} map[string]string "Not found"
 // @Failure 404 {object} map[string]string "Item not found"
+// @Failure 409 {object} map[string]string "Item was changed by someone else"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Router /shoplist/{id}/items/{itemId} [post]
 func (h *ShoplistHandler) UpdateShoplistItem(c *gin.Context) {
@@ -191,12 +252,22 @@ func (h *ShoplistHandler) UpdateShoplistItem(c *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "If-Match")
+		return
+	}
+
 	// Parse request body
 	var requestBody struct {
-		ItemName  *string `json:"item_name"`
-		BrandName *string `json:"brand_name"`
-		ExtraInfo *string `json:"extra_info"`
-		IsBought  *bool   `json:"is_bought"`
+		ItemName   *string  `json:"item_name"`
+		BrandName  *string  `json:"brand_name"`
+		ExtraInfo  *string  `json:"extra_info"`
+		Quantity   *float64 `json:"quantity"`
+		Unit       *string  `json:"unit"`
+		Category   *string  `json:"category"`
+		IsBought   *bool    `json:"is_bought"`
+		Recurrence *string  `json:"recurrence"`
 	}
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "item_name")
@@ -205,20 +276,45 @@ func (h *ShoplistHandler) UpdateShoplistItem(c *gin.Context) {
 
 	// Check if request body is empty
 	if requestBody.ItemName == nil && requestBody.BrandName == nil &&
-		requestBody.ExtraInfo == nil && requestBody.IsBought == nil {
+		requestBody.ExtraInfo == nil && requestBody.Quantity == nil &&
+		requestBody.Unit == nil && requestBody.Category == nil && requestBody.IsBought == nil &&
+		requestBody.Recurrence == nil {
 		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrMissingRequiredFieldUpdateShoplistItem)
 		return
 	}
 
-	updatedItem, shoplistErr := h.shoplistBiz.UpdateShoplistItem(c, userID, shoplistID, itemID, requestBody.ItemName, requestBody.BrandName, requestBody.ExtraInfo, requestBody.IsBought)
+	updatedItem, shoplistErr := h.shoplistBiz.UpdateShoplistItemFields(c, userID, shoplistID, itemID, bizshoplist.ShoplistItemUpdate{
+		ItemName:   requestBody.ItemName,
+		BrandName:  requestBody.BrandName,
+		ExtraInfo:  requestBody.ExtraInfo,
+		Quantity:   requestBody.Quantity,
+		Unit:       requestBody.Unit,
+		Category:   requestBody.Category,
+		IsBought:   requestBody.IsBought,
+		Recurrence: requestBody.Recurrence,
+	}, expectedVersion)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistVersionConflict:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistVersionConflict)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
 		case bizshoplist.ShoplistNotMember:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		case bizshoplist.ShoplistItemNotFound:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistItemNotFound)
+		case bizshoplist.ShoplistItemNameEmpty:
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "item_name")
+		case bizshoplist.ShoplistItemInvalidUnit:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemUnit)
+		case bizshoplist.ShoplistItemInvalidQuantity:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemQuantity)
+		case bizshoplist.ShoplistItemInvalidCategory:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemCategory)
+		case bizshoplist.ShoplistItemInvalidRecurrence:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemRecurrence)
 		case bizshoplist.ShoplistFailedToProcess:
 			logger.Errorf("UpdateShoplistItem: Failed to update item. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -235,8 +331,83 @@ func (h *ShoplistHandler) UpdateShoplistItem(c *gin.Context) {
 		"item_name":  updatedItem.ItemName,
 		"brand_name": updatedItem.BrandName,
 		"extra_info": updatedItem.ExtraInfo,
+		"quantity":   updatedItem.Quantity,
+		"unit":       updatedItem.Unit,
+		"category":   updatedItem.Category,
 		"is_bought":  updatedItem.IsBought,
+		"recurrence": updatedItem.Recurrence,
+		"version":    updatedItem.Version,
 	}
 
+	c.Header("ETag", formatETag(updatedItem.Version))
 	h.responseFactory.CreateOKResponse(c, respData)
 }
+
+// ReorderShoplistItems persists the order of the items in a shoplist
+// @Summary Reorder the items in a shoplist
+// @Description Sets the order of the items in a shoplist. Items that are not listed in the request, such as items added by another member in the meantime, are kept after the listed items. The user must be a member of the shoplist.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+//
+//	@Param request body struct {
+//	    ItemIDs []int `json:"item_ids" binding:"required"`
+//	} true "Item IDs in the new order"
+//
+// @Success 200 {object} map[string]interface{} "Successfully reordered items"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Item IDs must not be empty and must list each item of the shoplist at most once"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 404 {object} map[string]string "Item not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Router /shoplist/{id}/item/reorder [post]
+func (h *ShoplistHandler) ReorderShoplistItems(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("ReorderShoplistItems: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		ItemIDs []int `json:"item_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil || len(requestBody.ItemIDs) == 0 {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "item_ids")
+		return
+	}
+
+	order, shoplistErr := h.shoplistBiz.ReorderShoplistItems(c, userID, shoplistID, requestBody.ItemIDs)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistItemNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistItemNotFound)
+		case bizshoplist.ShoplistItemInvalidOrder:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistItemOrder)
+		default:
+			logger.Errorf("ReorderShoplistItems: Failed to reorder items. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{
+		"item_ids": order,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -77,8 +78,12 @@ func TestAddItemToShopListOwner(t *testing.T) {
 		"item_name":  "Test Item",
 		"brand_name": "Test Brand",
 		"extra_info": "Test Info",
+		"quantity":   float64(0),
+		"unit":       "",
+		"category":   "other",
 		"is_bought":  false,
 		"thumbnail":  "",
+		"version":    float64(1),
 	}, response)
 
 	// Verify database
@@ -163,8 +168,12 @@ func TestAddItemToShopListMember(t *testing.T) {
 		"item_name":  "Another Item",
 		"brand_name": "Another Brand",
 		"extra_info": "Another Info",
+		"quantity":   float64(0),
+		"unit":       "",
+		"category":   "other",
 		"is_bought":  false,
 		"thumbnail":  "",
+		"version":    float64(1),
 	}, response)
 
 	// Verify database
@@ -416,6 +425,59 @@ func TestAddItemToShopListEmptyName(t *testing.T) {
 	}, response)
 }
 
+func TestUpdateShoplistItemEmptyName(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user, shoplist and item
+	owner := db.User{ID: "owner-123", PostalCode: "238801"}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.Shoplist{ID: 1, OwnerID: owner.ID, Name: "Test Shoplist"}).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.ShoplistMember{ID: 1, ShopListID: 1, MemberID: owner.ID}).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.ShoplistItem{ID: 1, ShopListID: 1, ItemName: "Milk", Version: 1}).Error
+	assert.NoError(t, err)
+
+	update := func(itemName string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"item_name": itemName})
+		req, _ := http.NewRequest("POST", "/shoplist/1/items/1", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", owner.ID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+		shoplistHandler.UpdateShoplistItem(c)
+		return w
+	}
+
+	// Empty names and names of only spaces are rejected
+	for _, itemName := range []string{"", "   "} {
+		w := update(itemName)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"code": "GEN_00003", "error": "Missing field in body: item_name",
+		}, response)
+	}
+
+	// Names are stored trimmed
+	w := update("  Oat Milk  ")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var item db.ShoplistItem
+	err = testConn.GetDB().Where("id = ?", 1).First(&item).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "Oat Milk", item.ItemName)
+	assert.Equal(t, 2, item.Version)
+}
+
 func TestAddItemToShopListWithThumbnail(t *testing.T) {
 	shoplistHandler, testConn := setUpShoplistTestEnv(t)
 
@@ -482,8 +544,12 @@ func TestAddItemToShopListWithThumbnail(t *testing.T) {
 		"item_name":  "Test Item",
 		"brand_name": "Test Brand",
 		"extra_info": "Test Info",
+		"quantity":   float64(0),
+		"unit":       "",
+		"category":   "other",
 		"is_bought":  false,
 		"thumbnail":  "https://example.com/image.jpg",
+		"version":    float64(1),
 	}, response)
 
 	// Verify database
@@ -498,6 +564,241 @@ func TestAddItemToShopListWithThumbnail(t *testing.T) {
 	assert.Equal(t, 1, item.ShopListID)
 }
 
+func TestAddItemToShopListWithQuantityAndUnit(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create request with a lower case unit that should be normalized
+	requestBody := map[string]interface{}{
+		"item_name":  "Milk",
+		"brand_name": "Test Brand",
+		"quantity":   2,
+		"unit":       "l",
+	}
+	body, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("PUT", "/shoplist/1/items", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.AddItemToShopList(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         float64(1),
+		"item_name":  "Milk",
+		"brand_name": "Test Brand",
+		"extra_info": "",
+		"quantity":   float64(2),
+		"unit":       "L",
+		"category":   "dairy",
+		"is_bought":  false,
+		"thumbnail":  "",
+		"version":    float64(1),
+	}, response)
+
+	// Verify database
+	var item db.ShoplistItem
+	err = testConn.GetDB().First(&item, response["id"]).Error
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), item.Quantity)
+	assert.Equal(t, "L", item.Unit)
+}
+
+func TestAddItemToShopListParsed(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create request with free text, the brand sent in the request wins over the parsed brand
+	requestBody := map[string]interface{}{
+		"item_name":  "2 lbs Maple Leaf chicken breast",
+		"extra_info": "boneless",
+		"parse":      true,
+	}
+	body, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("PUT", "/shoplist/1/items", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.AddItemToShopList(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         float64(1),
+		"item_name":  "chicken breast",
+		"brand_name": "Maple Leaf",
+		"extra_info": "boneless",
+		"quantity":   float64(907),
+		"unit":       "g",
+		"category":   "meat_seafood",
+		"is_bought":  false,
+		"thumbnail":  "",
+		"version":    float64(1),
+	}, response)
+
+	// Without parse the text is stored verbatim
+	requestBody = map[string]interface{}{
+		"item_name": "2 lbs Maple Leaf chicken breast",
+	}
+	body, _ = json.Marshal(requestBody)
+	req, _ = http.NewRequest("PUT", "/shoplist/1/items", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.AddItemToShopList(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var item db.ShoplistItem
+	err = testConn.GetDB().Last(&item).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "2 lbs Maple Leaf chicken breast", item.ItemName)
+	assert.Equal(t, "", item.BrandName)
+}
+
+func TestAddItemToShopListInvalidUnit(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create request
+	requestBody := map[string]interface{}{
+		"item_name": "Milk",
+		"quantity":  2,
+		"unit":      "gallon",
+	}
+	body, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("PUT", "/shoplist/1/items", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.AddItemToShopList(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code": "SHP_00005", "error": "Unit must be one of g, kg, ml, L, each or pack.",
+	}, response)
+
+	// Verify no item was created
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
 func TestRemoveItemFromShopListOwner(t *testing.T) {
 	shoplistHandler, testConn := setUpShoplistTestEnv(t)
 
@@ -1025,7 +1326,12 @@ func TestUpdateShoplistItemOwner(t *testing.T) {
 		"item_name":  "Test Item",
 		"brand_name": "Test Brand",
 		"extra_info": "Test Info",
+		"quantity":   float64(0),
+		"unit":       "",
+		"category":   "other",
 		"is_bought":  true,
+		"recurrence": "",
+		"version":    float64(2),
 	}, response)
 
 	// Verify database
@@ -1128,7 +1434,12 @@ func TestUpdateShoplistItemMember(t *testing.T) {
 		"item_name":  "Test Item",
 		"brand_name": "Test Brand",
 		"extra_info": "Test Info",
+		"quantity":   float64(0),
+		"unit":       "",
+		"category":   "other",
 		"is_bought":  false,
+		"recurrence": "",
+		"version":    float64(2),
 	}, response)
 
 	// Verify database
@@ -1439,6 +1750,50 @@ func TestUpdateShoplistItemDifferentShoplist(t *testing.T) {
 	assert.NoError(t, err, "Item should still exist in database")
 }
 
+func TestUpdateShoplistItemRecurrence(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user, shoplist and item
+	owner := db.User{ID: "owner-123", PostalCode: "238801"}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.Shoplist{ID: 1, OwnerID: owner.ID, Name: "Test Shoplist"}).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.ShoplistMember{ID: 1, ShopListID: 1, MemberID: owner.ID}).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&db.ShoplistItem{ID: 1, ShopListID: 1, ItemName: "Milk", Version: 1}).Error
+	assert.NoError(t, err)
+
+	update := func(recurrence string) map[string]interface{} {
+		body, _ := json.Marshal(map[string]interface{}{"recurrence": recurrence})
+		req, _ := http.NewRequest("POST", "/shoplist/1/items/1", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", owner.ID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+		shoplistHandler.UpdateShoplistItem(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		return response
+	}
+
+	response := update("Weekly:Saturday")
+	assert.Equal(t, "weekly:saturday", response["recurrence"])
+
+	// A cleared recurrence is returned as empty
+	response = update("")
+	value, found := response["recurrence"]
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+}
+
 func TestUpdateShoplistItemEmptyRequest(t *testing.T) {
 	shoplistHandler, testConn := setUpShoplistTestEnv(t)
 
@@ -1520,3 +1875,225 @@ func TestUpdateShoplistItemEmptyRequest(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, existingItem.IsBought)
 }
+
+func TestReorderShoplistItems(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	nonMember := db.User{
+		ID:         "non-member-123",
+		PostalCode: "238802",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&nonMember).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create test items
+	for i := 1; i <= 4; i++ {
+		item := db.ShoplistItem{
+			ID:         i,
+			ShopListID: testShoplist.ID,
+			ItemName:   "Test Item " + strconv.Itoa(i),
+			Position:   i,
+		}
+		err = testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	reorder := func(userID string, itemIDs []int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"item_ids": itemIDs})
+		req, _ := http.NewRequest("POST", "/shoplist/1/item/reorder", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.ReorderShoplistItems(c)
+		return w
+	}
+
+	// Item 4 is not part of the request, as if it was added after the client loaded the list
+	w := reorder(owner.ID, []int{3, 1, 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"item_ids": []interface{}{float64(3), float64(1), float64(2), float64(4)},
+	}, response)
+
+	// Verify database
+	var items []db.ShoplistItem
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).Order("position").Find(&items).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(items))
+	assert.Equal(t, 3, items[0].ID)
+	assert.Equal(t, 1, items[1].ID)
+	assert.Equal(t, 2, items[2].ID)
+	assert.Equal(t, 4, items[3].ID)
+
+	// Duplicate item IDs
+	w = reorder(owner.ID, []int{1, 1})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var errResponse map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00008",
+		"error": "Item IDs must not be empty and must list each item of the shoplist at most once.",
+	}, errResponse)
+
+	// No item IDs
+	w = reorder(owner.ID, []int{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Item from another list
+	w = reorder(owner.ID, []int{1, 99})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Non-member
+	w = reorder(nonMember.ID, []int{1, 2})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateAndRemoveShoplistItemIfMatch(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "member-123", Nickname: "Member", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "editor"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Add test item to shoplist
+	item := db.ShoplistItem{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		ItemName:   "Test Item",
+	}
+	err = testConn.GetDB().Create(&item).Error
+	assert.NoError(t, err)
+
+	update := func(userID string, ifMatch string, requestBody map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(requestBody)
+		req, _ := http.NewRequest("POST", "/shoplist/1/items/1", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+		shoplistHandler.UpdateShoplistItem(c)
+		return w
+	}
+
+	// Both users start from version 1, the first update wins
+	w := update(users[0].ID, `"1"`, map[string]interface{}{"quantity": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = update(users[1].ID, `"1"`, map[string]interface{}{"quantity": 3})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "SHP_00024", response["code"])
+
+	var dbItem db.ShoplistItem
+	err = testConn.GetDB().First(&dbItem, item.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), dbItem.Quantity)
+	assert.Equal(t, 2, dbItem.Version)
+
+	// Invalid If-Match
+	w = update(users[1].ID, "2", map[string]interface{}{"quantity": 3})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Without If-Match the last write wins
+	w = update(users[1].ID, "", map[string]interface{}{"quantity": 3})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	remove := func(userID string, ifMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/shoplist/1/items/1", nil)
+		req.Header.Set("If-Match", ifMatch)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+		shoplistHandler.RemoveItemFromShopList(c)
+		return w
+	}
+
+	// Removing with a stale version keeps the item
+	w = remove(users[0].ID, `"2"`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("id = ?", item.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	w = remove(users[0].ID, `"3"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
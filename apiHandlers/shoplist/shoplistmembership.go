@@ -1,6 +1,8 @@
 package apiHandlersshoplist
 
 import (
+	"encoding/json"
+	"io"
 	"strconv"
 	"time"
 
@@ -13,7 +15,7 @@ import (
 
 // GetShoplistMembers returns the members of a shoplist
 // @Summary Get shoplist members
-// @Description Returns the members of a shoplist
+// @Description Returns the members of a shoplist and, separately, the users with a pending invitation to it
 // @Tags shoplist
 // @Accept json
 // @Produce json
@@ -40,7 +42,7 @@ func (h *ShoplistHandler) GetShoplistMembers(c *gin.Context) {
 		return
 	}
 
-	members, shoplistErr := h.shoplistBiz.GetShoplistMembers(c, userID, shoplistID)
+	members, invitees, shoplistErr := h.shoplistBiz.GetShoplistMembers(c, userID, shoplistID)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
@@ -60,23 +62,39 @@ func (h *ShoplistHandler) GetShoplistMembers(c *gin.Context) {
 		responseMembers = append(responseMembers, map[string]string{
 			"id":       member.ID,
 			"nickname": member.Nickname,
+			"role":     member.Role,
+		})
+	}
+
+	responseInvitees := make([]map[string]interface{}, 0)
+	for _, invitee := range invitees {
+		responseInvitees = append(responseInvitees, map[string]interface{}{
+			"invitation_id": invitee.InvitationID,
+			"id":            invitee.ID,
+			"nickname":      invitee.Nickname,
+			"role":          invitee.Role,
+			"invited_at":    invitee.InvitedAt.Format(time.RFC3339),
 		})
 	}
 
 	h.responseFactory.CreateOKResponse(c, map[string]interface{}{
-		"members": responseMembers,
+		"members":          responseMembers,
+		"pending_invitees": responseInvitees,
 	})
 }
 
 // LeaveShopList allows a user to leave a shoplist
 // @Summary Leave a shoplist
-// @Description Allows a user to leave a shoplist. If the user is the owner, ownership will be transferred to another member. If the user is the last member, the shoplist will be deleted.
+// @Description Allows a user to leave a shoplist. If the user is the owner, ownership will be transferred to the given successor, or to the longest-standing member if none is given. If the user is the last member, the shoplist will be deleted.
 // @Tags shoplist
 // @Accept json
 // @Produce json
 // @Param id path int true "Shoplist ID"
+// @Param successor_id body string false "Member to hand the ownership to when the owner leaves"
 // @Success 200 {object} map[string]interface{} "Successfully left the shoplist"
 // @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} map[string]string "New owner must be another member of the shoplist"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Failure 404 {object} map[string]string "Not found"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -97,13 +115,26 @@ func (h *ShoplistHandler) LeaveShopList(c *gin.Context) {
 		return
 	}
 
-	shoplistErr := h.shoplistBiz.LeaveShopList(c, userID, shoplistID)
+	// Parse the optional request body
+	var requestBody struct {
+		SuccessorID string `json:"successor_id"`
+	}
+	if c.Request.Body != nil {
+		if err := json.NewDecoder(c.Request.Body).Decode(&requestBody); err != nil && err != io.EOF {
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	shoplistErr := h.shoplistBiz.LeaveShopList(c, userID, shoplistID, requestBody.SuccessorID)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		case bizshoplist.ShoplistNotMember:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNewOwnerNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNewOwnerNotMember)
 		case bizshoplist.ShoplistFailedToProcess:
 			logger.Errorf("LeaveShopList: Failed to process shoplist. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -117,15 +148,236 @@ func (h *ShoplistHandler) LeaveShopList(c *gin.Context) {
 	h.responseFactory.CreateOKResponse(c, nil)
 }
 
+// TransferShoplistOwnership hands the ownership of a shoplist to another member
+// @Summary Transfer ownership
+// @Description Hands the ownership of a shoplist to another member. Only the owner can transfer ownership.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param new_owner_id body string true "Member to hand the ownership to"
+// @Success 200 {object} gin.H "Successfully transferred ownership"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "New owner is required"
+// @Failure 400 {object} map[string]string "New owner must be another member of the shoplist"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "Only the owner can transfer ownership"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/transfer-ownership [post]
+func (h *ShoplistHandler) TransferShoplistOwnership(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("TransferShoplistOwnership: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		NewOwnerID string `json:"new_owner_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "new_owner_id")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.TransferShoplistOwnership(c, userID, shoplistID, requestBody.NewOwnerID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		case bizshoplist.ShoplistNewOwnerNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNewOwnerNotMember)
+		default:
+			logger.Errorf("TransferShoplistOwnership: Failed to transfer ownership. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
+
+// RemoveShoplistMember removes a member from a shoplist
+// @Summary Remove a member
+// @Description Removes a member from a shoplist. Only the owner can remove members. When ban is true, the removed user cannot rejoin the shoplist with any share code.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param memberId path string true "Member ID"
+// @Param ban query bool false "Stop the removed user from rejoining"
+// @Success 200 {object} gin.H "Successfully removed the member"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid ban flag"
+// @Failure 400 {object} map[string]string "Owner cannot remove themselves"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "Only the owner can remove members"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 404 {object} map[string]string "Member not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/members/{memberId} [delete]
+func (h *ShoplistHandler) RemoveShoplistMember(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("RemoveShoplistMember: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Get member ID from URL
+	memberID := c.Param("memberId")
+	if memberID == "" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "memberId")
+		return
+	}
+
+	ban := false
+	if banParam := c.Query("ban"); banParam != "" {
+		ban, err = strconv.ParseBool(banParam)
+		if err != nil {
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "ban")
+			return
+		}
+	}
+
+	shoplistErr := h.shoplistBiz.RemoveShoplistMember(c, userID, shoplistID, memberID, ban)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		case bizshoplist.ShoplistCannotRemoveSelf:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistCannotRemoveSelf)
+		case bizshoplist.ShoplistMemberNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberNotFound)
+		default:
+			logger.Errorf("RemoveShoplistMember: Failed to remove member. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
+
+// UpdateShoplistMemberRole changes the role of a member
+// @Summary Change member role
+// @Description Changes the role of a member to editor or viewer. Viewers can read the shoplist but cannot modify its items. Only the owner can change roles.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param memberId path string true "Member ID"
+// @Param role body string true "New role, editor or viewer"
+// @Success 200 {object} gin.H "Successfully changed the role"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Role is required"
+// @Failure 400 {object} map[string]string "Role must be editor or viewer"
+// @Failure 400 {object} map[string]string "Owner role can only change through an ownership transfer"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 403 {object} map[string]string "Only the owner can change roles"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 404 {object} map[string]string "Member not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/members/{memberId}/role [post]
+func (h *ShoplistHandler) UpdateShoplistMemberRole(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("UpdateShoplistMemberRole: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// Get member ID from URL
+	memberID := c.Param("memberId")
+	if memberID == "" {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "memberId")
+		return
+	}
+
+	// Parse request body
+	var requestBody struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredField, "role")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.UpdateShoplistMemberRole(c, userID, shoplistID, memberID, requestBody.Role)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotMember:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		case bizshoplist.ShoplistInvalidMemberRole:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistMemberRole)
+		case bizshoplist.ShoplistCannotChangeOwnerRole:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistCannotChangeOwnerRole)
+		case bizshoplist.ShoplistMemberNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberNotFound)
+		default:
+			logger.Errorf("UpdateShoplistMemberRole: Failed to update role. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
+
 // RequestShopListShareCode generates a share code for a shoplist
 // @Summary Generate share code
-// @Description Generates a unique share code for a shoplist that can be used by other users to join. Only the owner can generate share codes. The code expires in 24 hours.
+// @Description Generates a unique share code for a shoplist that can be used by other users to join. Only the owner can generate share codes. The code expires in 24 hours unless another expiry is given, allows unlimited joins unless max_uses is set, and grants the editor role unless another role is given. A new code replaces the previous one.
 // @Tags shoplist
 // @Accept json
 // @Produce json
 // @Param id path int true "Shoplist ID"
+// @Param expires_in_minutes body int false "Minutes until the code expires, between 1 minute and 30 days"
+// @Param max_uses body int false "Maximum number of joins, 0 for unlimited"
+// @Param role body string false "Role granted on join, editor or viewer"
 // @Success 200 {object} map[string]interface{} "Successfully generated share code"
 // @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid request body"
+// @Failure 400 {object} map[string]string "Share code expiry must be between 1 minute and 30 days"
+// @Failure 400 {object} map[string]string "Max uses must not be negative"
+// @Failure 400 {object} map[string]string "Role must be editor or viewer"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Failure 403 {object} map[string]string "Only the owner can generate share codes"
 // @Failure 404 {object} map[string]string "Not found"
@@ -147,7 +399,26 @@ func (h *ShoplistHandler) RequestShopListShareCode(c *gin.Context) {
 		return
 	}
 
-	shareCode, shoplistErr := h.shoplistBiz.RequestShopListShareCode(c, userID, shoplistID)
+	// Parse the optional request body
+	var requestBody struct {
+		ExpiresInMinutes int    `json:"expires_in_minutes"`
+		MaxUses          int    `json:"max_uses"`
+		Role             string `json:"role"`
+	}
+	if c.Request.Body != nil {
+		if err := json.NewDecoder(c.Request.Body).Decode(&requestBody); err != nil && err != io.EOF {
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	expiresIn := time.Duration(requestBody.ExpiresInMinutes) * time.Minute
+	if requestBody.ExpiresInMinutes < 0 {
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShareCodeExpiry)
+		return
+	}
+
+	shareCode, shoplistErr := h.shoplistBiz.RequestShopListShareCode(c, userID, shoplistID, expiresIn, requestBody.MaxUses, requestBody.Role)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistNotFound:
@@ -156,6 +427,12 @@ func (h *ShoplistHandler) RequestShopListShareCode(c *gin.Context) {
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		case bizshoplist.ShoplistNotOwner:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		case bizshoplist.ShoplistInvalidShareCodeExpiry:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShareCodeExpiry)
+		case bizshoplist.ShoplistInvalidShareCodeMaxUses:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShareCodeMaxUses)
+		case bizshoplist.ShoplistInvalidMemberRole:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShoplistMemberRole)
 		case bizshoplist.ShoplistFailedToProcess:
 			logger.Errorf("RequestShopListShareCode: Failed to process shoplist. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -169,6 +446,8 @@ func (h *ShoplistHandler) RequestShopListShareCode(c *gin.Context) {
 	respData := map[string]interface{}{
 		"share_code": shareCode.Code,
 		"expires_at": shareCode.Expiry.Format(time.RFC3339),
+		"max_uses":   shareCode.MaxUses,
+		"role":       shareCode.Role,
 	}
 
 	h.responseFactory.CreateOKResponse(c, respData)
@@ -228,7 +507,7 @@ func (h *ShoplistHandler) RevokeShopListShareCode(c *gin.Context) {
 
 // JoinShopList allows a user to join a shoplist using a share code
 // @Summary Join a shoplist using a share code
-// @Description Allows a user to join a shoplist by providing a valid share code. The share code must be active and not expired.
+// @Description Allows a user to join a shoplist by providing a valid share code. The share code must be active and not expired. Too many invalid codes lock the user and the client IP out for a while.
 // @Tags shoplist
 // @Accept json
 // @Produce json
@@ -236,6 +515,9 @@ func (h *ShoplistHandler) RevokeShopListShareCode(c *gin.Context) {
 // @Success 200 {object} gin.H "Successfully joined the shoplist"
 // @Failure 400 {object} map[string]string "Share code is required"
 // @Failure 400 {object} map[string]string "Invalid share code"
+// @Failure 400 {object} map[string]string "Share code has reached its maximum number of uses"
+// @Failure 403 {object} map[string]string "User is not allowed to join this shoplist"
+// @Failure 429 {object} map[string]string "Too many failed attempts"
 // @Failure 404 {object} map[string]string "Not found"
 // @Failure 401 {object} map[string]string "User not authenticated"
 // @Router /shoplist/join [post]
@@ -257,7 +539,7 @@ func (h *ShoplistHandler) JoinShopList(c *gin.Context) {
 		return
 	}
 
-	shoplistErr := h.shoplistBiz.JoinShopList(c, userID, requestBody.ShareCode)
+	shoplistErr := h.shoplistBiz.JoinShopList(c, userID, c.ClientIP(), requestBody.ShareCode)
 	if shoplistErr != nil {
 		switch shoplistErr.ErrCode {
 		case bizshoplist.ShoplistFailedToProcess:
@@ -267,6 +549,14 @@ func (h *ShoplistHandler) JoinShopList(c *gin.Context) {
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
 		case bizshoplist.ShoplistNotMember:
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberBanned:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberBanned)
+		case bizshoplist.ShoplistShareCodeExhausted:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShareCodeExhausted)
+		case bizshoplist.ShoplistInvalidShareCode:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidShareCode)
+		case bizshoplist.ShoplistTooManyJoinAttempts:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrTooManyJoinAttempts)
 		default:
 			logger.Errorf("JoinShopList: Failed to join shoplist. Error: %s", shoplistErr.Error())
 			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
@@ -1,11 +1,13 @@
 package apiHandlersshoplist
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -315,6 +317,76 @@ func TestLeaveShopListLastMember(t *testing.T) {
 	assert.Equal(t, int64(0), remainingMemberCount, "No members should remain")
 }
 
+func TestLeaveShopListLastMembersConcurrently(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member-123", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist with two members
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	for i, user := range users {
+		member := db.ShoplistMember{
+			ID:         i + 1,
+			ShopListID: testShoplist.ID,
+			MemberID:   user.ID,
+		}
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Both members leave at the same time
+	statuses := make([]int, len(users))
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(i int, userID string) {
+			defer wg.Done()
+
+			req, _ := http.NewRequest("POST", "/shoplist/1/leave", nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set("userID", userID)
+			c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+			shoplistHandler.LeaveShopList(c)
+			statuses[i] = w.Code
+		}(i, user.ID)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses)
+
+	// The one who left last moved the shoplist to the trash
+	var shoplist db.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	err = testConn.GetDB().Unscoped().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+	assert.True(t, shoplist.DeletedAt.Valid)
+
+	var memberCount int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ?", testShoplist.ID).Count(&memberCount).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), memberCount)
+}
+
 func TestLeaveShopListNonMember(t *testing.T) {
 	// Setup test database
 	shoplistHandler, testConn := setUpShoplistTestEnv(t)
@@ -1019,6 +1091,7 @@ func TestGetShoplistMembers(t *testing.T) {
 		ID:         3,
 		ShopListID: shoplist.ID,
 		MemberID:   member2.ID,
+		Role:       "viewer",
 	}
 	err = testConn.GetDB().Create(&ownerMember).Error
 	assert.NoError(t, err)
@@ -1066,6 +1139,17 @@ func TestGetShoplistMembers(t *testing.T) {
 		assert.Equal(t, "Owner", memberMap[owner.ID])
 		assert.Equal(t, "Member 1", memberMap[member1.ID])
 		assert.Equal(t, "Member 2", memberMap[member2.ID])
+
+		// Verify member roles
+		roleMap := make(map[string]string)
+		for _, m := range members {
+			member := m.(map[string]interface{})
+			roleMap[member["id"].(string)] = member["role"].(string)
+		}
+
+		assert.Equal(t, "owner", roleMap[owner.ID])
+		assert.Equal(t, "editor", roleMap[member1.ID])
+		assert.Equal(t, "viewer", roleMap[member2.ID])
 	})
 
 	t.Run("Success - Get members as member", func(t *testing.T) {
@@ -1242,3 +1326,758 @@ func TestGetShoplistMembers(t *testing.T) {
 		}, response)
 	})
 }
+
+func TestLeaveShopListOwnerWithSuccessor(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member1-123", PostalCode: "238802"},
+		{ID: "member2-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	for i, user := range users {
+		member := db.ShoplistMember{
+			ID:         i + 1,
+			ShopListID: testShoplist.ID,
+			MemberID:   user.ID,
+		}
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	leave := func(successorID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"successor_id": successorID})
+		req, _ := http.NewRequest("POST", "/shoplist/1/leave", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", users[0].ID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.LeaveShopList(c)
+		return w
+	}
+
+	// Successor must be a member
+	w := leave("stranger-123")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00009",
+		"error": "New owner must be another member of the shoplist.",
+	}, response)
+
+	// Hand ownership to the second member
+	w = leave(users[2].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var shoplist db.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, users[2].ID, shoplist.OwnerID)
+}
+
+func TestLeaveShopListOwnerLongestStandingMember(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member1-123", PostalCode: "238802"},
+		{ID: "member2-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist, the second member joined before the first one
+	now := time.Now()
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID},
+		{ID: 3, ShopListID: testShoplist.ID, MemberID: users[2].ID},
+	}
+	members[0].CreatedAt = now.Add(-3 * time.Hour)
+	members[1].CreatedAt = now.Add(-1 * time.Hour)
+	members[2].CreatedAt = now.Add(-2 * time.Hour)
+	for _, member := range members {
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Create request without a successor
+	req, _ := http.NewRequest("POST", "/shoplist/1/leave", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", users[0].ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.LeaveShopList(c)
+
+	// Assert response
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Verify ownership went to the longest-standing member
+	var shoplist db.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, users[2].ID, shoplist.OwnerID)
+}
+
+func TestTransferShoplistOwnership(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member-123", PostalCode: "238802"},
+		{ID: "stranger-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner and member to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID},
+	}
+	for _, member := range members {
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	transfer := func(userID string, newOwnerID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"new_owner_id": newOwnerID})
+		req, _ := http.NewRequest("POST", "/shoplist/1/transfer-ownership", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.TransferShoplistOwnership(c)
+		return w
+	}
+
+	// Non-owner cannot transfer ownership
+	w := transfer(users[1].ID, users[1].ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// New owner must be a member
+	w = transfer(users[0].ID, users[2].ID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Owner transfers ownership to the member
+	w = transfer(users[0].ID, users[1].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var shoplist db.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, users[1].ID, shoplist.OwnerID)
+
+	// Previous owner is still a member
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[0].ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRemoveShoplistMember(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member1-123", PostalCode: "238802"},
+		{ID: "member2-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	for i, user := range users {
+		member := db.ShoplistMember{
+			ID:         i + 1,
+			ShopListID: testShoplist.ID,
+			MemberID:   user.ID,
+		}
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Create an active share code
+	shareCode := db.ShoplistShareCode{
+		ShopListID: testShoplist.ID,
+		Code:       "ABC123",
+		Expiry:     time.Now().Add(24 * time.Hour),
+	}
+	err = testConn.GetDB().Create(&shareCode).Error
+	assert.NoError(t, err)
+
+	remove := func(userID string, memberID string, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/shoplist/1/members/"+memberID+query, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "memberId", Value: memberID}}
+
+		shoplistHandler.RemoveShoplistMember(c)
+		return w
+	}
+
+	join := func(userID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"share_code": shareCode.Code})
+		req, _ := http.NewRequest("POST", "/shoplist/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+
+		shoplistHandler.JoinShopList(c)
+		return w
+	}
+
+	// Non-owner cannot remove members
+	w := remove(users[1].ID, users[2].ID, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Owner cannot remove themselves
+	w = remove(users[0].ID, users[0].ID, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Unknown member
+	w = remove(users[0].ID, "stranger-123", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Remove without ban, user can rejoin
+	w = remove(users[0].ID, users[1].ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = join(users[1].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Remove with ban, user cannot rejoin
+	w = remove(users[0].ID, users[2].ID, "?ban=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = join(users[2].ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00012",
+		"error": "User is not allowed to join this shoplist.",
+	}, response)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[2].ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// The removed member is soft-deleted
+	var removed db.ShoplistMember
+	err = testConn.GetDB().Unscoped().Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[2].ID).First(&removed).Error
+	assert.NoError(t, err)
+	assert.True(t, removed.DeletedAt.Valid)
+
+	// The member that rejoined got their row back
+	var rejoined []db.ShoplistMember
+	err = testConn.GetDB().Unscoped().Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[1].ID).Find(&rejoined).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rejoined))
+	assert.False(t, rejoined[0].DeletedAt.Valid)
+}
+
+func TestUpdateShoplistMemberRole(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "member-123", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner and member to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID},
+	}
+	for _, member := range members {
+		err = testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	updateRole := func(userID string, memberID string, role string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"role": role})
+		req, _ := http.NewRequest("POST", "/shoplist/1/members/"+memberID+"/role", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "memberId", Value: memberID}}
+
+		shoplistHandler.UpdateShoplistMemberRole(c)
+		return w
+	}
+
+	addItem := func(userID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"item_name": "Milk"})
+		req, _ := http.NewRequest("PUT", "/shoplist/1/item", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.AddItemToShopList(c)
+		return w
+	}
+
+	// Non-owner cannot change roles
+	w := updateRole(users[1].ID, users[1].ID, "viewer")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Unknown role
+	w = updateRole(users[0].ID, users[1].ID, "admin")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Owner role cannot be changed
+	w = updateRole(users[0].ID, users[0].ID, "viewer")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Editors can add items
+	w = addItem(users[1].ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Viewers cannot add items
+	w = updateRole(users[0].ID, users[1].ID, "viewer")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = addItem(users[1].ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00013",
+		"error": "Viewers cannot modify items.",
+	}, response)
+
+	var member db.ShoplistMember
+	err = testConn.GetDB().Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[1].ID).First(&member).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "viewer", member.Role)
+}
+
+func TestRequestShopListShareCodeWithOptions(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "joiner1-123", PostalCode: "238802"},
+		{ID: "joiner2-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   users[0].ID,
+		Role:       "owner",
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	requestShareCode := func(options map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(options)
+		req, _ := http.NewRequest("POST", "/shoplist/1/share-code", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", users[0].ID)
+		c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+		shoplistHandler.RequestShopListShareCode(c)
+		return w
+	}
+
+	join := func(userID string, shareCode string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"share_code": shareCode})
+		req, _ := http.NewRequest("POST", "/shoplist/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+
+		shoplistHandler.JoinShopList(c)
+		return w
+	}
+
+	// Invalid options
+	w := requestShareCode(map[string]interface{}{"role": "owner"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = requestShareCode(map[string]interface{}{"max_uses": -1})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = requestShareCode(map[string]interface{}{"expires_in_minutes": 31 * 24 * 60})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Single use viewer code that expires in an hour
+	w = requestShareCode(map[string]interface{}{"expires_in_minutes": 60, "max_uses": 1, "role": "viewer"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), response["max_uses"])
+	assert.Equal(t, "viewer", response["role"])
+
+	expiryTime, err := time.Parse(time.RFC3339, response["expires_at"].(string))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiryTime, time.Second)
+
+	shareCode := response["share_code"].(string)
+
+	// First join gets the viewer role
+	w = join(users[1].ID, shareCode)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var member db.ShoplistMember
+	err = testConn.GetDB().Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[1].ID).First(&member).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "viewer", member.Role)
+
+	// Second join is rejected
+	w = join(users[2].ID, shareCode)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errResponse map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00018",
+		"error": "Share code has reached its maximum number of uses.",
+	}, errResponse)
+
+	var dbShareCode db.ShoplistShareCode
+	err = testConn.GetDB().Where("shop_list_id = ?", testShoplist.ID).First(&dbShareCode).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dbShareCode.UseCount)
+}
+
+func TestJoinShopListTooManyAttempts(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "joiner-123", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Create an active share code
+	shareCode := db.ShoplistShareCode{
+		ShopListID: testShoplist.ID,
+		Code:       "ABC123",
+		Expiry:     time.Now().Add(24 * time.Hour),
+	}
+	err = testConn.GetDB().Create(&shareCode).Error
+	assert.NoError(t, err)
+
+	join := func(code string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"share_code": code})
+		req, _ := http.NewRequest("POST", "/shoplist/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", users[1].ID)
+
+		shoplistHandler.JoinShopList(c)
+		return w
+	}
+
+	// Invalid codes are rejected until the user is locked out
+	for i := 0; i < 5; i++ {
+		w := join("ZZZZZZ")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "SHP_00022", response["code"])
+	}
+
+	// Even the valid code is rejected during the lockout
+	w := join(shareCode.Code)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"code":  "SHP_00023",
+		"error": "Too many attempts, please try again later.",
+	}, response)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, users[1].ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestJoinShopListTooManyConcurrentAttempts(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	joiner := db.User{ID: "joiner-123", PostalCode: "238802"}
+	err := testConn.GetDB().Create(&joiner).Error
+	assert.NoError(t, err)
+
+	// Guess codes in parallel, every guess is counted before the code is looked up
+	const attempts = 10
+	statuses := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(map[string]interface{}{"share_code": "ZZZZZ" + strconv.Itoa(i)})
+			req, _ := http.NewRequest("POST", "/shoplist/join", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set("userID", joiner.ID)
+
+			shoplistHandler.JoinShopList(c)
+			statuses[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	// Only the allowed number of guesses reach the share codes
+	counts := make(map[int]int)
+	for _, status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, map[int]int{
+		http.StatusBadRequest:      5,
+		http.StatusTooManyRequests: 5,
+	}, counts)
+}
+
+func TestJoinShopListOnAttemptLimit(t *testing.T) {
+	// Setup test database
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", PostalCode: "238801"},
+		{ID: "joiner1-123", PostalCode: "238802"},
+		{ID: "joiner2-123", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Create an active share code
+	shareCode := db.ShoplistShareCode{
+		ShopListID: testShoplist.ID,
+		Code:       "ABC123",
+		Expiry:     time.Now().Add(24 * time.Hour),
+	}
+	err = testConn.GetDB().Create(&shareCode).Error
+	assert.NoError(t, err)
+
+	// The first IP is one attempt away from its limit
+	err = testConn.GetDB().Create(&db.ShoplistJoinAttempt{AttemptKey: "ip:10.0.0.1", FailedCount: 19, WindowStart: time.Now()}).Error
+	assert.NoError(t, err)
+
+	join := func(userID string, clientIP string, code string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"share_code": code})
+		req, _ := http.NewRequest("POST", "/shoplist/join", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = clientIP + ":12345"
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+
+		shoplistHandler.JoinShopList(c)
+		return w
+	}
+
+	// A successful join on the limit of the IP does not lock the IP out
+	w := join(users[1].ID, "10.0.0.1", shareCode.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var attempt db.ShoplistJoinAttempt
+	err = testConn.GetDB().Where("attempt_key = ?", "ip:10.0.0.1").First(&attempt).Error
+	assert.NoError(t, err)
+	assert.Nil(t, attempt.LockedUntil)
+	assert.Equal(t, 19, attempt.FailedCount)
+
+	// A successful join on the limit of the user does not lock the user out
+	for i := 0; i < 4; i++ {
+		w = join(users[2].ID, "10.0.0.2", "ZZZZZZ")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+	w = join(users[2].ID, "10.0.0.2", shareCode.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistJoinAttempt{}).Where("attempt_key = ?", "user:"+users[2].ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	err = testConn.GetDB().Where("attempt_key = ?", "ip:10.0.0.2").First(&attempt).Error
+	assert.NoError(t, err)
+	assert.Nil(t, attempt.LockedUntil)
+	assert.Equal(t, 4, attempt.FailedCount)
+
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id IN ?", testShoplist.ID, []string{users[1].ID, users[2].ID}).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,81 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// GetItemSuggestions returns items to suggest when adding to a shoplist
+// @Summary Get item suggestions
+// @Description Returns items bought or added before in the shoplist and in the other shoplists of the user, ranked by how often and how recently they were used.
+// @Description Items on the shoplist that are not bought yet are left out. Pass what the user typed as prefix to autocomplete the item name.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param prefix query string false "Start of the item name"
+// @Param limit query int false "Number of suggestions, up to 50"
+// @Success 200 {object} map[string]interface{} "Successfully got item suggestions"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid limit"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/suggestions [get]
+func (h *ShoplistHandler) GetItemSuggestions(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetItemSuggestions: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	limit := bizshoplist.DefaultItemSuggestions
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > bizshoplist.MaxItemSuggestions {
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "limit")
+			return
+		}
+	}
+
+	suggestions, shoplistErr := h.shoplistBiz.GetItemSuggestions(c, userID, shoplistID, c.Query("prefix"), limit)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		default:
+			logger.Errorf("GetItemSuggestions: Failed to get item suggestions. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	response := make([]ItemSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		response = append(response, ItemSuggestionResponse{
+			ItemName:   suggestion.ItemName,
+			BrandName:  suggestion.BrandName,
+			Unit:       suggestion.Unit,
+			Category:   suggestion.Category,
+			Count:      suggestion.ShoplistCount + suggestion.OtherCount,
+			LastUsedAt: suggestion.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{"suggestions": response})
+}
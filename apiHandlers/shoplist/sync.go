@@ -0,0 +1,119 @@
+package apiHandlersshoplist
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// SyncShoplists returns the changes to the shoplists of a user since a cursor
+// @Summary Sync shoplists
+// @Description Returns the shoplists, items and members that were created, changed or deleted since the cursor, with tombstones for deleted shoplists, items and members. Without a cursor, or when reset is true in the response, the response is the full state and replaces any local copy. Pass the returned cursor as since on the next sync.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned by the previous sync"
+// @Success 200 {object} SyncResponse "Successfully synced shoplists"
+// @Failure 400 {object} map[string]string "Invalid since"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/sync [get]
+func (h *ShoplistHandler) SyncShoplists(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("SyncShoplists: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	var since time.Time
+	if cursor := c.Query("since"); cursor != "" {
+		var err error
+		since, err = bizshoplist.DecodeSyncCursor(cursor)
+		if err != nil {
+			h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrInvalidParam, "since")
+			return
+		}
+	}
+
+	changes, shoplistErr := h.shoplistBiz.SyncShoplists(c, userID, since)
+	if shoplistErr != nil {
+		logger.Errorf("SyncShoplists: Failed to sync shoplists. Error: %s", shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	response := SyncResponse{
+		Cursor:           changes.Cursor,
+		Reset:            changes.Reset,
+		Shoplists:        make([]SyncShoplistResponse, 0, len(changes.Shoplists)),
+		DeletedShoplists: changes.DeletedShoplistIDs,
+		Items:            make([]SyncItemResponse, 0, len(changes.Items)),
+		DeletedItems:     make([]SyncItemTombstone, 0, len(changes.DeletedItems)),
+		Members:          make([]SyncMemberResponse, 0, len(changes.Members)),
+		DeletedMembers:   make([]SyncMemberTombstone, 0, len(changes.DeletedMembers)),
+	}
+
+	for _, shoplist := range changes.Shoplists {
+		response.Shoplists = append(response.Shoplists, SyncShoplistResponse{
+			ID:   shoplist.ID,
+			Name: shoplist.Name,
+			Owner: OwnerResponse{
+				ID:       shoplist.OwnerID,
+				Nickname: shoplist.OwnerNickname,
+			},
+			Version:    shoplist.Version,
+			IsTemplate: shoplist.IsTemplate,
+			UpdatedAt:  shoplist.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	for _, item := range changes.Items {
+		response.Items = append(response.Items, SyncItemResponse{
+			ID:         item.ID,
+			ShoplistID: item.ShopListID,
+			Name:       item.ItemName,
+			BrandName:  item.BrandName,
+			ExtraInfo:  item.ExtraInfo,
+			Quantity:   item.Quantity,
+			Unit:       item.Unit,
+			Category:   item.Category,
+			IsBought:   item.IsBought,
+			Position:   item.Position,
+			Thumbnail:  item.Thumbnail,
+			Version:    item.Version,
+			Recurrence: item.Recurrence,
+			UpdatedAt:  item.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	for _, item := range changes.DeletedItems {
+		response.DeletedItems = append(response.DeletedItems, SyncItemTombstone{
+			ID:         item.ID,
+			ShoplistID: item.ShopListID,
+		})
+	}
+
+	for _, member := range changes.Members {
+		response.Members = append(response.Members, SyncMemberResponse{
+			ShoplistID: member.ShopListID,
+			ID:         member.MemberID,
+			Nickname:   member.Nickname,
+			Role:       member.Role,
+		})
+	}
+
+	for _, member := range changes.DeletedMembers {
+		response.DeletedMembers = append(response.DeletedMembers, SyncMemberTombstone{
+			ShoplistID: member.ShopListID,
+			ID:         member.MemberID,
+		})
+	}
+
+	h.responseFactory.CreateOKResponse(c, response)
+}
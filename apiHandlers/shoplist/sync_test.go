@@ -0,0 +1,133 @@
+package apiHandlersshoplist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestSyncShoplists(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Everything is created before the cursor used below
+	createdAt := time.Now().Add(-time.Hour)
+	model := gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt}
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "member-123", Nickname: "Member", PostalCode: "238802"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		Model:   model,
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Test Shoplist",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{Model: model, ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{Model: model, ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "editor"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Add test items to shoplist
+	items := []db.ShoplistItem{
+		{Model: model, ID: 1, ShopListID: testShoplist.ID, ItemName: "Unchanged Item"},
+		{Model: model, ID: 2, ShopListID: testShoplist.ID, ItemName: "Updated Item"},
+		{Model: model, ID: 3, ShopListID: testShoplist.ID, ItemName: "Removed Item"},
+	}
+	for _, item := range items {
+		err := testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	syncShoplists := func(userID string, since string) (*httptest.ResponseRecorder, SyncResponse) {
+		req, _ := http.NewRequest("GET", "/shoplist/sync?since="+since, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", userID)
+
+		shoplistHandler.SyncShoplists(c)
+
+		var response SyncResponse
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+		}
+		return w, response
+	}
+
+	// A sync without a cursor returns everything
+	w, response := syncShoplists(users[0].ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, response.Reset)
+	assert.NotEmpty(t, response.Cursor)
+	assert.Equal(t, 1, len(response.Shoplists))
+	assert.Equal(t, 3, len(response.Items))
+	assert.Equal(t, 2, len(response.Members))
+
+	// An invalid cursor
+	w, _ = syncShoplists(users[0].ID, "not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Change the shoplist after the cursor
+	cursor := bizshoplist.EncodeSyncCursor(time.Now().Add(-30 * time.Minute))
+	ctx := context.Background()
+	quantity := float64(2)
+	_, shoplistErr := shoplistHandler.shoplistBiz.UpdateShoplistItem(ctx, users[0].ID, testShoplist.ID, 2, nil, nil, nil, &quantity, nil, nil, nil, bizshoplist.AnyVersion)
+	assert.Nil(t, shoplistErr)
+	shoplistErr = shoplistHandler.shoplistBiz.RemoveItemFromShopList(ctx, users[0].ID, testShoplist.ID, 3, bizshoplist.AnyVersion)
+	assert.Nil(t, shoplistErr)
+	shoplistErr = shoplistHandler.shoplistBiz.LeaveShopList(ctx, users[1].ID, testShoplist.ID, "")
+	assert.Nil(t, shoplistErr)
+
+	// The owner only gets the changes
+	w, response = syncShoplists(users[0].ID, cursor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, response.Reset)
+	assert.Equal(t, 0, len(response.Shoplists))
+	assert.Equal(t, 1, len(response.Items))
+	assert.Equal(t, 2, response.Items[0].ID)
+	assert.Equal(t, float64(2), response.Items[0].Quantity)
+	assert.Equal(t, []SyncItemTombstone{{ID: 3, ShoplistID: testShoplist.ID}}, response.DeletedItems)
+	assert.Equal(t, 0, len(response.Members))
+	assert.Equal(t, []SyncMemberTombstone{{ShoplistID: testShoplist.ID, ID: users[1].ID}}, response.DeletedMembers)
+	assert.Equal(t, 0, len(response.DeletedShoplists))
+
+	// The member that left gets a tombstone for the shoplist
+	w, response = syncShoplists(users[1].ID, cursor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, len(response.Shoplists))
+	assert.Equal(t, 0, len(response.Items))
+	assert.Equal(t, []int{testShoplist.ID}, response.DeletedShoplists)
+
+	// A cursor older than the trash retention returns everything, as the tombstones may have been purged
+	retainingBiz := bizshoplist.InitializeShoplistBiz(*testConn, bizshoplist.NewInProcessShoplistEventBroker(0), 10*time.Minute)
+	changes, shoplistErr := retainingBiz.SyncShoplists(ctx, users[0].ID, time.Now().Add(-30*time.Minute))
+	assert.Nil(t, shoplistErr)
+	assert.True(t, changes.Reset)
+	assert.Equal(t, 0, len(changes.DeletedItems))
+}
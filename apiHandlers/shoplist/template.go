@@ -0,0 +1,177 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// CloneShoplist copies a shoplist into a new shoplist owned by the user
+// @Summary Clone a shoplist
+// @Description Copies a shoplist the user is a member of into a new shoplist owned by the user. The items are copied in their order.
+// @Description With reset_bought the items are copied as not bought, with include_members the other members are copied with their roles. Only the owner can include the members.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param request body struct{Name string; ResetBought bool; IncludeMembers bool} false "Clone options, an empty name keeps the name of the shoplist"
+// @Success 201 {object} map[string]interface{} "ID of the new shoplist"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid JSON"
+// @Failure 403 {object} map[string]string "Only the owner can include the members"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/clone [post]
+func (h *ShoplistHandler) CloneShoplist(c *gin.Context) {
+	var req struct {
+		Name           string `json:"name"`
+		ResetBought    bool   `json:"reset_bought"`
+		IncludeMembers bool   `json:"include_members"`
+	}
+	h.createFromShoplist(c, "CloneShoplist", &req, func(userID string, shoplistID int) (int, *bizshoplist.ShoplistError) {
+		return h.shoplistBiz.CloneShoplist(c, userID, shoplistID, bizshoplist.CloneShoplistOptions{
+			Name:           req.Name,
+			ResetBought:    req.ResetBought,
+			IncludeMembers: req.IncludeMembers,
+		})
+	})
+}
+
+// SaveShoplistAsTemplate saves a copy of a shoplist as a template
+// @Summary Save a shoplist as a template
+// @Description Saves a copy of a shoplist the user is a member of as a template owned by the user. Templates are not returned with the shoplists of the user and their items are not bought.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param request body struct{Name string} false "Template name, an empty name keeps the name of the shoplist"
+// @Success 201 {object} map[string]interface{} "ID of the template"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid JSON"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/template [post]
+func (h *ShoplistHandler) SaveShoplistAsTemplate(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	h.createFromShoplist(c, "SaveShoplistAsTemplate", &req, func(userID string, shoplistID int) (int, *bizshoplist.ShoplistError) {
+		return h.shoplistBiz.SaveShoplistAsTemplate(c, userID, shoplistID, req.Name)
+	})
+}
+
+// InstantiateShoplistTemplate creates a new shoplist from a template
+// @Summary Instantiate a template
+// @Description Creates a new shoplist owned by the user from a template the user is a member of.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body struct{Name string} false "Shoplist name, an empty name keeps the name of the template"
+// @Success 201 {object} map[string]interface{} "ID of the new shoplist"
+// @Failure 400 {object} map[string]string "Invalid template ID"
+// @Failure 400 {object} map[string]string "Invalid JSON"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/template/{id}/instantiate [post]
+func (h *ShoplistHandler) InstantiateShoplistTemplate(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	h.createFromShoplist(c, "InstantiateShoplistTemplate", &req, func(userID string, templateID int) (int, *bizshoplist.ShoplistError) {
+		return h.shoplistBiz.InstantiateShoplistTemplate(c, userID, templateID, req.Name)
+	})
+}
+
+// createFromShoplist reads the optional request body and creates a shoplist from the shoplist in the URL, responding
+// with the ID of the new shoplist
+func (h *ShoplistHandler) createFromShoplist(c *gin.Context, handlerName string, req interface{}, create func(userID string, shoplistID int) (int, *bizshoplist.ShoplistError)) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("%s: User ID is empty.", handlerName)
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	// The body is optional
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInvalidRequestBody)
+		return
+	}
+
+	newID, shoplistErr := create(userID, shoplistID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistNotOwner:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotOwned)
+		default:
+			logger.Errorf("%s: Failed to create shoplist. Error: %s", handlerName, shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateCreatedResponse(c, map[string]interface{}{
+		"id": newID,
+	})
+}
+
+// GetShoplistTemplates retrieves the templates of a user
+// @Summary Get the templates of a user
+// @Description Retrieves the templates the user is a member of with the number of items in each template.
+// @Tags shoplist
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Successfully retrieved templates"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/template [get]
+func (h *ShoplistHandler) GetShoplistTemplates(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetShoplistTemplates: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	templates, shoplistErr := h.shoplistBiz.GetShoplistTemplatesForUser(c, userID)
+	if shoplistErr != nil {
+		logger.Errorf("GetShoplistTemplates: Failed to get templates. Error: %s", shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	response := make([]ShoplistTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		response = append(response, ShoplistTemplateResponse{
+			ID:   template.ID,
+			Name: template.Name,
+			Owner: OwnerResponse{
+				ID:       template.OwnerID,
+				Nickname: template.OwnerNickname,
+			},
+			ItemCount: template.ItemCount,
+		})
+	}
+
+	h.responseFactory.CreateOKResponse(c, map[string]interface{}{"templates": response})
+}
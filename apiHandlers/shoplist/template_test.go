@@ -0,0 +1,231 @@
+package apiHandlersshoplist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestCloneShoplistAndTemplates(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	users := []db.User{
+		{ID: "owner-123", Nickname: "Owner", PostalCode: "238801"},
+		{ID: "viewer-123", Nickname: "Viewer", PostalCode: "238802"},
+		{ID: "outsider-123", Nickname: "Outsider", PostalCode: "238803"},
+	}
+	for _, user := range users {
+		err := testConn.GetDB().Create(&user).Error
+		assert.NoError(t, err)
+	}
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: users[0].ID,
+		Name:    "Weekly staples",
+	}
+	err := testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add members to shoplist
+	members := []db.ShoplistMember{
+		{ID: 1, ShopListID: testShoplist.ID, MemberID: users[0].ID, Role: "owner"},
+		{ID: 2, ShopListID: testShoplist.ID, MemberID: users[1].ID, Role: "viewer"},
+	}
+	for _, member := range members {
+		err := testConn.GetDB().Create(&member).Error
+		assert.NoError(t, err)
+	}
+
+	// Add test items to shoplist, eggs come back weekly
+	eggsDueAt := time.Now().AddDate(0, 0, 7)
+	items := []db.ShoplistItem{
+		{ID: 1, ShopListID: testShoplist.ID, ItemName: "Milk", Position: 2, Version: 1},
+		{ID: 2, ShopListID: testShoplist.ID, ItemName: "Eggs", Position: 1, Version: 1, IsBought: true, Recurrence: "days:7", RecurrenceDueAt: &eggsDueAt},
+	}
+	for _, item := range items {
+		err := testConn.GetDB().Create(&item).Error
+		assert.NoError(t, err)
+	}
+
+	create := func(handler gin.HandlerFunc, userID string, id int, body string) (*httptest.ResponseRecorder, int) {
+		req, _ := http.NewRequest("POST", "/shoplist/"+strconv.Itoa(id), bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = []gin.Param{{Key: "id", Value: strconv.Itoa(id)}}
+		c.Set("userID", userID)
+
+		handler(c)
+
+		var response struct {
+			ID int `json:"id"`
+		}
+		if w.Code == http.StatusCreated {
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+		}
+		return w, response.ID
+	}
+
+	getShoplist := func(id int) (db.Shoplist, []db.ShoplistItem, []db.ShoplistMember) {
+		var shoplist db.Shoplist
+		err := testConn.GetDB().First(&shoplist, id).Error
+		assert.NoError(t, err)
+		var storedItems []db.ShoplistItem
+		err = testConn.GetDB().Where("shop_list_id = ?", id).Order("position, id").Find(&storedItems).Error
+		assert.NoError(t, err)
+		var storedMembers []db.ShoplistMember
+		err = testConn.GetDB().Where("shop_list_id = ?", id).Order("id").Find(&storedMembers).Error
+		assert.NoError(t, err)
+		return shoplist, storedItems, storedMembers
+	}
+
+	// Test case 1: Clone with reset bought and members
+	{
+		w, cloneID := create(shoplistHandler.CloneShoplist, users[0].ID, testShoplist.ID, `{"name": "Next week", "reset_bought": true, "include_members": true}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		clone, clonedItems, clonedMembers := getShoplist(cloneID)
+		assert.Equal(t, "Next week", clone.Name)
+		assert.Equal(t, users[0].ID, clone.OwnerID)
+		assert.False(t, clone.IsTemplate)
+		assert.Len(t, clonedItems, 2)
+		assert.Equal(t, "Eggs", clonedItems[0].ItemName)
+		assert.Equal(t, "Milk", clonedItems[1].ItemName)
+		for _, item := range clonedItems {
+			assert.False(t, item.IsBought)
+			assert.Nil(t, item.RecurrenceDueAt)
+		}
+		assert.Equal(t, "days:7", clonedItems[0].Recurrence)
+		assert.Equal(t, "", clonedItems[1].Recurrence)
+		assert.Len(t, clonedMembers, 2)
+		assert.Equal(t, users[0].ID, clonedMembers[0].MemberID)
+		assert.Equal(t, "owner", clonedMembers[0].Role)
+		assert.Equal(t, users[1].ID, clonedMembers[1].MemberID)
+		assert.Equal(t, "viewer", clonedMembers[1].Role)
+	}
+
+	// Test case 1b: Other members cannot copy the members
+	{
+		w, _ := create(shoplistHandler.CloneShoplist, users[1].ID, testShoplist.ID, `{"include_members": true}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var count int64
+		err := testConn.GetDB().Model(&db.Shoplist{}).Where("owner_id = ?", users[1].ID).Count(&count).Error
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		// Without the members any member can clone
+		w, cloneID := create(shoplistHandler.CloneShoplist, users[1].ID, testShoplist.ID, `{"reset_bought": true}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		_, _, clonedMembers := getShoplist(cloneID)
+		assert.Len(t, clonedMembers, 1)
+	}
+
+	// Test case 2: Clone items only without a body
+	{
+		w, cloneID := create(shoplistHandler.CloneShoplist, users[0].ID, testShoplist.ID, "")
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		clone, clonedItems, clonedMembers := getShoplist(cloneID)
+		assert.Equal(t, "Weekly staples", clone.Name)
+		assert.Len(t, clonedItems, 2)
+		assert.True(t, clonedItems[0].IsBought)
+		assert.Len(t, clonedMembers, 1)
+
+		// Eggs that stay bought come back with the source item
+		assert.Equal(t, "days:7", clonedItems[0].Recurrence)
+		if assert.NotNil(t, clonedItems[0].RecurrenceDueAt) {
+			assert.WithinDuration(t, eggsDueAt, *clonedItems[0].RecurrenceDueAt, time.Second)
+		}
+		assert.Nil(t, clonedItems[1].RecurrenceDueAt)
+	}
+
+	// Test case 3: Non members cannot clone
+	{
+		w, _ := create(shoplistHandler.CloneShoplist, users[2].ID, testShoplist.ID, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w, _ = create(shoplistHandler.CloneShoplist, users[0].ID, 999, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+
+	// Test case 4: Templates are listed separately from the shoplists
+	var templateID int
+	{
+		var w *httptest.ResponseRecorder
+		w, templateID = create(shoplistHandler.SaveShoplistAsTemplate, users[0].ID, testShoplist.ID, `{"name": "Staples"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		template, templateItems, _ := getShoplist(templateID)
+		assert.True(t, template.IsTemplate)
+		for _, item := range templateItems {
+			assert.False(t, item.IsBought)
+		}
+
+		req, _ := http.NewRequest("GET", "/shoplist", nil)
+		w = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", users[0].ID)
+		shoplistHandler.GetAllShoplistAndItemsForUser(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"name":"Staples"`)
+
+		req, _ = http.NewRequest("GET", "/shoplist/template", nil)
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", users[0].ID)
+		shoplistHandler.GetShoplistTemplates(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"templates": []interface{}{
+				map[string]interface{}{
+					"id":   float64(templateID),
+					"name": "Staples",
+					"owner": map[string]interface{}{
+						"id":       users[0].ID,
+						"nickname": "Owner",
+					},
+					"item_count": float64(2),
+				},
+			},
+		}, response)
+	}
+
+	// Test case 5: Instantiate a template
+	{
+		w, shoplistID := create(shoplistHandler.InstantiateShoplistTemplate, users[0].ID, templateID, `{"name": "Week 12"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		shoplist, shoplistItems, _ := getShoplist(shoplistID)
+		assert.Equal(t, "Week 12", shoplist.Name)
+		assert.False(t, shoplist.IsTemplate)
+		assert.Len(t, shoplistItems, 2)
+
+		// Only templates can be instantiated
+		w, _ = create(shoplistHandler.InstantiateShoplistTemplate, users[0].ID, testShoplist.ID, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		// Only members of the template can instantiate it
+		w, _ = create(shoplistHandler.InstantiateShoplistTemplate, users[1].ID, templateID, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package apiHandlersshoplist
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kdjuwidja/aishoppercommon/logger"
+
+	"netherealmstudio.com/m/v2/apiHandlers"
+	bizshoplist "netherealmstudio.com/m/v2/biz/shoplist"
+)
+
+// GetTrash returns the deleted shoplists and items of a user
+// @Summary Get the trash
+// @Description Returns the deleted shoplists owned by the user and the deleted items of the shoplists the user is a member of. Deleted shoplists and items are purged permanently after the retention window.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Success 200 {object} TrashResponse "Successfully got the trash"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/trash [get]
+func (h *ShoplistHandler) GetTrash(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("GetTrash: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	shoplists, items, shoplistErr := h.shoplistBiz.GetTrash(c, userID)
+	if shoplistErr != nil {
+		logger.Errorf("GetTrash: Failed to get trash. Error: %s", shoplistErr.Error())
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	response := TrashResponse{
+		Shoplists: make([]TrashedShoplistResponse, 0, len(shoplists)),
+		Items:     make([]TrashedItemResponse, 0, len(items)),
+	}
+	for _, shoplist := range shoplists {
+		response.Shoplists = append(response.Shoplists, TrashedShoplistResponse{
+			ID:        shoplist.ID,
+			Name:      shoplist.Name,
+			DeletedAt: shoplist.DeletedAt.Format(time.RFC3339),
+		})
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, TrashedItemResponse{
+			ID:           item.ID,
+			ShoplistID:   item.ShopListID,
+			ShoplistName: item.ShopListName,
+			Name:         item.ItemName,
+			BrandName:    item.BrandName,
+			DeletedAt:    item.DeletedAt.Format(time.RFC3339),
+		})
+	}
+
+	h.responseFactory.CreateOKResponse(c, response)
+}
+
+// RestoreShoplist restores a deleted shoplist
+// @Summary Restore a deleted shoplist
+// @Description Restores a deleted shoplist together with the members and items that were deleted with it. Only the owner can restore a shoplist.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Success 200 {object} gin.H "Successfully restored shoplist"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/restore [post]
+func (h *ShoplistHandler) RestoreShoplist(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("RestoreShoplist: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Get shoplist ID from URL
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.RestoreShoplist(c, userID, shoplistID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		default:
+			logger.Errorf("RestoreShoplist: Failed to restore shoplist. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
+
+// RestoreShoplistItem restores a deleted item
+// @Summary Restore a deleted item
+// @Description Restores a deleted item into its shoplist. The user must be a member of the shoplist.
+// @Tags shoplist
+// @Accept json
+// @Produce json
+// @Param id path int true "Shoplist ID"
+// @Param itemId path int true "Item ID"
+// @Success 200 {object} gin.H "Successfully restored item"
+// @Failure 400 {object} map[string]string "Invalid shoplist ID"
+// @Failure 400 {object} map[string]string "Invalid item ID"
+// @Failure 401 {object} map[string]string "User not authenticated"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 404 {object} map[string]string "Item not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /shoplist/{id}/item/{itemId}/restore [post]
+func (h *ShoplistHandler) RestoreShoplistItem(c *gin.Context) {
+	// Get user ID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		logger.Errorf("RestoreShoplistItem: User ID is empty.")
+		h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		return
+	}
+
+	// Parse shoplist ID and item ID from URL parameters
+	shoplistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "id")
+		return
+	}
+
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		h.responseFactory.CreateErrorResponsef(c, apiHandlers.ErrMissingRequiredParam, "itemId")
+		return
+	}
+
+	shoplistErr := h.shoplistBiz.RestoreShoplistItem(c, userID, shoplistID, itemID)
+	if shoplistErr != nil {
+		switch shoplistErr.ErrCode {
+		case bizshoplist.ShoplistNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistNotFound)
+		case bizshoplist.ShoplistMemberReadOnly:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistMemberReadOnly)
+		case bizshoplist.ShoplistItemNotFound:
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrShoplistItemNotFound)
+		default:
+			logger.Errorf("RestoreShoplistItem: Failed to restore item. Error: %s", shoplistErr.Error())
+			h.responseFactory.CreateErrorResponse(c, apiHandlers.ErrInternalServerError)
+		}
+		return
+	}
+
+	h.responseFactory.CreateOKResponse(c, nil)
+}
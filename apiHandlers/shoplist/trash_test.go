@@ -0,0 +1,224 @@
+package apiHandlersshoplist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestRemoveAndRestoreShoplistItem(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test user
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create test item
+	item := db.ShoplistItem{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		ItemName:   "Test Item",
+		BrandName:  "Test Brand",
+	}
+	err = testConn.GetDB().Create(&item).Error
+	assert.NoError(t, err)
+
+	// Remove the item
+	req, _ := http.NewRequest("DELETE", "/shoplist/1/item/1", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+	shoplistHandler.RemoveItemFromShopList(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Verify the item is soft deleted
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("id = ?", item.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	err = testConn.GetDB().Unscoped().Model(&db.ShoplistItem{}).Where("id = ?", item.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// The item shows up in the trash
+	req, _ = http.NewRequest("GET", "/shoplist/trash", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+
+	shoplistHandler.GetTrash(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var trash TrashResponse
+	err = json.Unmarshal(w.Body.Bytes(), &trash)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(trash.Shoplists))
+	assert.Equal(t, 1, len(trash.Items))
+	assert.Equal(t, item.ID, trash.Items[0].ID)
+	assert.Equal(t, testShoplist.ID, trash.Items[0].ShoplistID)
+	assert.Equal(t, "Test Shoplist", trash.Items[0].ShoplistName)
+	assert.Equal(t, "Test Item", trash.Items[0].Name)
+
+	// Restore the item
+	req, _ = http.NewRequest("POST", "/shoplist/1/item/1/restore", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+	shoplistHandler.RestoreShoplistItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("id = ?", item.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Restoring an item that is not deleted fails
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "itemId", Value: "1"}}
+
+	shoplistHandler.RestoreShoplistItem(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLeaveAndRestoreShoplist(t *testing.T) {
+	shoplistHandler, testConn := setUpShoplistTestEnv(t)
+
+	// Create test users
+	owner := db.User{
+		ID:         "owner-123",
+		PostalCode: "238801",
+	}
+	other := db.User{
+		ID:         "other-123",
+		PostalCode: "238802",
+	}
+	err := testConn.GetDB().Create(&owner).Error
+	assert.NoError(t, err)
+	err = testConn.GetDB().Create(&other).Error
+	assert.NoError(t, err)
+
+	// Create test shoplist
+	testShoplist := db.Shoplist{
+		ID:      1,
+		OwnerID: owner.ID,
+		Name:    "Test Shoplist",
+	}
+	err = testConn.GetDB().Create(&testShoplist).Error
+	assert.NoError(t, err)
+
+	// Add owner as member to shoplist
+	ownerMember := db.ShoplistMember{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		MemberID:   owner.ID,
+	}
+	err = testConn.GetDB().Create(&ownerMember).Error
+	assert.NoError(t, err)
+
+	// Create test item
+	item := db.ShoplistItem{
+		ID:         1,
+		ShopListID: testShoplist.ID,
+		ItemName:   "Test Item",
+	}
+	err = testConn.GetDB().Create(&item).Error
+	assert.NoError(t, err)
+
+	// Last member leaves, moving the shoplist to the trash
+	req, _ := http.NewRequest("POST", "/shoplist/1/leave", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.LeaveShopList(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The shoplist shows up in the owner's trash, but its items are not listed separately
+	req, _ = http.NewRequest("GET", "/shoplist/trash", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+
+	shoplistHandler.GetTrash(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var trash TrashResponse
+	err = json.Unmarshal(w.Body.Bytes(), &trash)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(trash.Shoplists))
+	assert.Equal(t, testShoplist.ID, trash.Shoplists[0].ID)
+	assert.Equal(t, 0, len(trash.Items))
+
+	// Only the owner can restore the shoplist
+	req, _ = http.NewRequest("POST", "/shoplist/1/restore", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", other.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.RestoreShoplist(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", owner.ID)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	shoplistHandler.RestoreShoplist(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Shoplist, membership and item are back
+	var shoplist db.Shoplist
+	err = testConn.GetDB().First(&shoplist, testShoplist.ID).Error
+	assert.NoError(t, err)
+
+	var count int64
+	err = testConn.GetDB().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", testShoplist.ID, owner.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = testConn.GetDB().Model(&db.ShoplistItem{}).Where("shop_list_id = ?", testShoplist.ID).Count(&count).Error
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
@@ -17,7 +17,7 @@ func (b *MatchShoplistItemsWithFlyerBiz) GetShoplistItems(ctx context.Context, u
 		SELECT shoplist_items.id as id, 
 			   shoplist_items.item_name as item_name, 
 			   shoplist_items.brand_name as brand_name
-		FROM (SELECT * FROM shoplist_members WHERE member_id = ?) as tbl1
+		FROM (SELECT * FROM shoplist_members WHERE member_id = ? AND deleted_at IS NULL) as tbl1
 		LEFT JOIN shoplist_items
 		ON shoplist_items.shop_list_id = tbl1.shop_list_id
 		WHERE shoplist_items.id IN (?)
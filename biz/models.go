@@ -6,12 +6,18 @@ type ShoplistItem struct {
 	ItemName   string
 	BrandName  string
 	ExtraInfo  string
+	Quantity   float64
+	Unit       string
+	Category   string
 	IsBought   bool
+	Version    int
+	Recurrence string
 }
 
 type ShoplistMember struct {
 	ID       string
 	Nickname string
+	Role     string
 }
 
 type Shoplist struct {
@@ -19,7 +25,10 @@ type Shoplist struct {
 	Name          string
 	OwnerID       string
 	OwnerNickname string
+	Version       int
 	Items         []ShoplistItem
+	// HasMoreItems is set when only the first items of the shoplist were requested and there are more
+	HasMoreItems bool
 }
 
 type Flyer struct {
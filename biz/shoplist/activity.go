@@ -0,0 +1,127 @@
+package bizshoplist
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// Activity actions recorded for the mutations of a shoplist
+const (
+	ActivityShoplistCreated    = "shoplist_created"
+	ActivityShoplistRenamed    = "shoplist_renamed"
+	ActivityShoplistDeleted    = "shoplist_deleted"
+	ActivityShoplistRestored   = "shoplist_restored"
+	ActivityShoplistCloned     = "shoplist_cloned"
+	ActivityItemAdded          = "item_added"
+	ActivityItemUpdated        = "item_updated"
+	ActivityItemRemoved        = "item_removed"
+	ActivityItemRestored       = "item_restored"
+	ActivityItemRecurred       = "item_recurred"
+	ActivityItemsReordered     = "items_reordered"
+	ActivityItemsMarkedBought  = "items_marked_bought"
+	ActivityItemsUnmarked      = "items_unmarked"
+	ActivityBoughtItemsCleared = "bought_items_cleared"
+	ActivityMemberJoined       = "member_joined"
+	ActivityMemberLeft         = "member_left"
+	ActivityMemberRemoved      = "member_removed"
+	ActivityMemberRoleChanged  = "member_role_changed"
+	ActivityOwnershipChanged   = "ownership_changed"
+	ActivityShareCodeIssued    = "share_code_issued"
+	ActivityShareCodeRevoked   = "share_code_revoked"
+	ActivityInvitationSent     = "invitation_sent"
+	ActivityInvitationDeclined = "invitation_declined"
+)
+
+// Activity page sizes
+const (
+	DefaultShoplistActivityPage = 50
+	MaxShoplistActivityPage     = 100
+)
+
+// activityValues holds the before or after values of an activity
+type activityValues map[string]interface{}
+
+// itemActivityValues captures the fields of an item that members can change
+func itemActivityValues(item db.ShoplistItem) activityValues {
+	return activityValues{
+		"item_name":  item.ItemName,
+		"brand_name": item.BrandName,
+		"extra_info": item.ExtraInfo,
+		"quantity":   item.Quantity,
+		"unit":       item.Unit,
+		"category":   item.Category,
+		"is_bought":  item.IsBought,
+	}
+}
+
+// recordActivity adds an entry to the activity log of a shoplist. itemID is 0 when the activity is not about an item.
+// gormDB Context already established before calling this function
+func recordActivity(tx *gorm.DB, shoplistID int, actorID string, action string, itemID int, before activityValues, after activityValues) error {
+	activity := db.ShoplistActivity{
+		ShopListID: shoplistID,
+		ActorID:    actorID,
+		Action:     action,
+	}
+	if itemID != 0 {
+		activity.ItemID = &itemID
+	}
+
+	if before != nil {
+		beforeJSON, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		activity.BeforeValues = string(beforeJSON)
+	}
+
+	if after != nil {
+		afterJSON, err := json.Marshal(after)
+		if err != nil {
+			return err
+		}
+		activity.AfterValues = string(afterJSON)
+	}
+
+	if err := tx.Create(&activity).Error; err != nil {
+		return err
+	}
+
+	addPendingShoplistEvent(tx, activity)
+	return nil
+}
+
+// GetShoplistActivity returns a page of the activity log of a shoplist, newest first. The page starts after the
+// activity with ID beforeID, or at the newest activity when beforeID is 0. The user must be a member of the shoplist.
+func (b *ShoplistBiz) GetShoplistActivity(ctx context.Context, userID string, shoplistID int, beforeID int, limit int) ([]ShoplistActivity, *ShoplistError) {
+	if _, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID); !isMember {
+		return nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	if limit <= 0 {
+		limit = DefaultShoplistActivityPage
+	}
+	if limit > MaxShoplistActivityPage {
+		limit = MaxShoplistActivityPage
+	}
+
+	query := b.dbPool.GetDB().WithContext(ctx).Table("shoplist_activities").
+		Select(`shoplist_activities.id as id, shoplist_activities.actor_id as actor_id, users.nickname as actor_nickname,
+			shoplist_activities.action as action, shoplist_activities.item_id as item_id,
+			shoplist_activities.before_values as before_values, shoplist_activities.after_values as after_values,
+			shoplist_activities.created_at as created_at`).
+		Joins("LEFT JOIN users ON shoplist_activities.actor_id = users.id").
+		Where("shoplist_activities.shop_list_id = ? AND shoplist_activities.deleted_at IS NULL", shoplistID)
+	if beforeID > 0 {
+		query = query.Where("shoplist_activities.id < ?", beforeID)
+	}
+
+	activities := make([]ShoplistActivity, 0)
+	if err := query.Order("shoplist_activities.id DESC").Limit(limit).Scan(&activities).Error; err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get activity.")
+	}
+
+	return activities, nil
+}
@@ -0,0 +1,129 @@
+package bizshoplist
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// MarkAllItemsBought marks every item of a shoplist that is not bought yet as bought and returns the IDs of those items
+func (b *ShoplistBiz) MarkAllItemsBought(ctx context.Context, userID string, shoplistID int) ([]int, *ShoplistError) {
+	return b.setAllItemsBought(ctx, userID, shoplistID, true, ActivityItemsMarkedBought)
+}
+
+// UnmarkAllItemsBought marks every bought item of a shoplist as not bought and returns the IDs of those items
+func (b *ShoplistBiz) UnmarkAllItemsBought(ctx context.Context, userID string, shoplistID int) ([]int, *ShoplistError) {
+	return b.setAllItemsBought(ctx, userID, shoplistID, false, ActivityItemsUnmarked)
+}
+
+// setAllItemsBought sets the bought status of all items of a shoplist in a single statement. The change is recorded as
+// one activity that lists the changed items.
+func (b *ShoplistBiz) setAllItemsBought(ctx context.Context, userID string, shoplistID int, isBought bool, action string) ([]int, *ShoplistError) {
+	if accessErr := b.checkItemEditAccess(ctx, userID, shoplistID); accessErr != nil {
+		return nil, accessErr
+	}
+
+	itemIDs := make([]int, 0)
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		itemIDs, err = lockItemsByBought(tx, shoplistID, !isBought)
+		if err != nil || len(itemIDs) == 0 {
+			return err
+		}
+
+		// Items that are not bought are never due, recurring items that are bought get their due time below
+		if err := tx.Model(&db.ShoplistItem{}).Where("id IN ?", itemIDs).Updates(map[string]interface{}{
+			"is_bought":         isBought,
+			"recurrence_due_at": nil,
+			"version":           gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+
+		if isBought {
+			now := time.Now()
+			var items []db.ShoplistItem
+			if err := tx.Where("id IN ?", itemIDs).Order("position, id").Find(&items).Error; err != nil {
+				return err
+			}
+			if err := scheduleRecurringItems(tx, items, now); err != nil {
+				return err
+			}
+			if err := recordPurchases(tx, userID, items, now); err != nil {
+				return err
+			}
+		}
+
+		return recordActivity(tx, shoplistID, userID, action, 0, nil, activityValues{"item_ids": itemIDs})
+	}); err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to update items.")
+	}
+
+	return itemIDs, nil
+}
+
+// ClearBoughtItems moves every bought item of a shoplist to the trash in a single statement and returns the IDs of
+// those items. The change is recorded as one activity that lists the removed items.
+func (b *ShoplistBiz) ClearBoughtItems(ctx context.Context, userID string, shoplistID int) ([]int, *ShoplistError) {
+	if accessErr := b.checkItemEditAccess(ctx, userID, shoplistID); accessErr != nil {
+		return nil, accessErr
+	}
+
+	itemIDs := make([]int, 0)
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		itemIDs, err = lockItemsByBought(tx, shoplistID, true)
+		if err != nil || len(itemIDs) == 0 {
+			return err
+		}
+
+		// Soft delete the items so that they can be restored from the trash
+		if err := tx.Where("id IN ?", itemIDs).Delete(&db.ShoplistItem{}).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityBoughtItemsCleared, 0, activityValues{"item_ids": itemIDs}, nil)
+	}); err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to clear bought items.")
+	}
+
+	return itemIDs, nil
+}
+
+// lockItemsByBought locks the items of a shoplist with the bought status and returns their IDs. The lock keeps single
+// item updates from changing the items between the select and the set-based update.
+// gormDB Context already established before calling this function
+func lockItemsByBought(tx *gorm.DB, shoplistID int, isBought bool) ([]int, error) {
+	itemIDs := make([]int, 0)
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&db.ShoplistItem{}).
+		Where("shop_list_id = ? AND is_bought = ?", shoplistID, isBought).Order("position, id").Pluck("id", &itemIDs).Error
+	return itemIDs, err
+}
+
+// scheduleRecurringItems sets when the recurring items among items that were just marked as bought come back. Items
+// with the same rule share a due time and are updated in a single statement.
+// gormDB Context already established before calling this function
+func scheduleRecurringItems(tx *gorm.DB, items []db.ShoplistItem, now time.Time) error {
+	itemIDsByRule := make(map[string][]int)
+	rules := make([]string, 0)
+	for _, item := range items {
+		if item.Recurrence == "" {
+			continue
+		}
+		if _, found := itemIDsByRule[item.Recurrence]; !found {
+			rules = append(rules, item.Recurrence)
+		}
+		itemIDsByRule[item.Recurrence] = append(itemIDsByRule[item.Recurrence], item.ID)
+	}
+
+	for _, rule := range rules {
+		dueAt := recurrenceDueAt(db.ShoplistItem{}, rule, true, now)
+		if err := tx.Model(&db.ShoplistItem{}).Where("id IN ?", itemIDsByRule[rule]).Update("recurrence_due_at", dueAt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
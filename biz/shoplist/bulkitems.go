@@ -0,0 +1,141 @@
+package bizshoplist
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// MaxBulkItems is the largest number of items that can be added, updated or removed in one request
+const MaxBulkItems = 100
+
+// BulkItemResult is the outcome for one item of a bulk request. Err is set when the item was not changed.
+type BulkItemResult struct {
+	Item *db.ShoplistItem
+	Err  *ShoplistError
+}
+
+// NewShoplistItem holds the fields of an item to add in bulk
+type NewShoplistItem struct {
+	ItemName  string
+	BrandName string
+	ExtraInfo string
+	Thumbnail string
+	Quantity  float64
+	Unit      string
+	Category  string
+}
+
+// ShoplistItemChange is the update of one item in a bulk update. ExpectedVersion works like for a single update.
+type ShoplistItemChange struct {
+	ItemID          int
+	Fields          ShoplistItemUpdate
+	ExpectedVersion int
+}
+
+// ShoplistItemRemoval is the removal of one item in a bulk removal. ExpectedVersion works like for a single removal.
+type ShoplistItemRemoval struct {
+	ItemID          int
+	ExpectedVersion int
+}
+
+// checkBulkItemAccess checks once for the whole request that the user can edit the items of the shoplist
+func (b *ShoplistBiz) checkBulkItemAccess(ctx context.Context, userID string, shoplistID int, count int) *ShoplistError {
+	if count == 0 {
+		return NewShoplistError(ShoplistInvalidBulkItems, "Items are required.")
+	}
+	if count > MaxBulkItems {
+		return NewShoplistError(ShoplistTooManyBulkItems, "Too many items.")
+	}
+
+	return b.checkItemEditAccess(ctx, userID, shoplistID)
+}
+
+// runBulkItems applies fn to every item of a bulk request in one transaction. Items that fail with a *ShoplistError
+// are reported in their result and the other items are still applied, any other error rolls back the whole request.
+func (b *ShoplistBiz) runBulkItems(ctx context.Context, count int, fn func(tx *gorm.DB, i int) (*db.ShoplistItem, error)) ([]BulkItemResult, error) {
+	results := make([]BulkItemResult, count)
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		for i := 0; i < count; i++ {
+			item, err := fn(tx, i)
+			if err != nil {
+				itemErr, ok := err.(*ShoplistError)
+				if !ok {
+					return err
+				}
+				results[i] = BulkItemResult{Err: itemErr}
+				continue
+			}
+			results[i] = BulkItemResult{Item: item}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AddItemsToShopList adds many items to the end of a shoplist in one transaction, in the order given.
+// There is a result for every item, items that fail validation are not added.
+func (b *ShoplistBiz) AddItemsToShopList(ctx context.Context, userID string, shoplistID int, items []NewShoplistItem) ([]BulkItemResult, *ShoplistError) {
+	if accessErr := b.checkBulkItemAccess(ctx, userID, shoplistID, len(items)); accessErr != nil {
+		return nil, accessErr
+	}
+
+	results, err := b.runBulkItems(ctx, len(items), func(tx *gorm.DB, i int) (*db.ShoplistItem, error) {
+		newItem, itemErr := newShoplistItem(shoplistID, items[i].ItemName, items[i].BrandName, items[i].ExtraInfo, items[i].Thumbnail, items[i].Quantity, items[i].Unit, items[i].Category)
+		if itemErr != nil {
+			return nil, itemErr
+		}
+
+		if err := insertShoplistItem(tx, userID, &newItem); err != nil {
+			return nil, err
+		}
+		return &newItem, nil
+	})
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToCreate, "Failed to add items.")
+	}
+
+	return results, nil
+}
+
+// UpdateShoplistItems updates many items of a shoplist in one transaction. There is a result for every item, items
+// that do not exist, fail validation or changed since their expected version are not updated.
+func (b *ShoplistBiz) UpdateShoplistItems(ctx context.Context, userID string, shoplistID int, changes []ShoplistItemChange) ([]BulkItemResult, *ShoplistError) {
+	if accessErr := b.checkBulkItemAccess(ctx, userID, shoplistID, len(changes)); accessErr != nil {
+		return nil, accessErr
+	}
+
+	results, err := b.runBulkItems(ctx, len(changes), func(tx *gorm.DB, i int) (*db.ShoplistItem, error) {
+		return updateShoplistItem(tx, userID, shoplistID, changes[i].ItemID, changes[i].Fields, changes[i].ExpectedVersion)
+	})
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to update items.")
+	}
+
+	return results, nil
+}
+
+// RemoveItemsFromShopList moves many items of a shoplist to the trash in one transaction. There is a result for every
+// item, items that do not exist or changed since their expected version are not removed.
+func (b *ShoplistBiz) RemoveItemsFromShopList(ctx context.Context, userID string, shoplistID int, removals []ShoplistItemRemoval) ([]BulkItemResult, *ShoplistError) {
+	if accessErr := b.checkBulkItemAccess(ctx, userID, shoplistID, len(removals)); accessErr != nil {
+		return nil, accessErr
+	}
+
+	results, err := b.runBulkItems(ctx, len(removals), func(tx *gorm.DB, i int) (*db.ShoplistItem, error) {
+		if err := trashShoplistItem(tx, userID, shoplistID, removals[i].ItemID, removals[i].ExpectedVersion); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to remove items.")
+	}
+
+	return results, nil
+}
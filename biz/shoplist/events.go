@@ -0,0 +1,183 @@
+package bizshoplist
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// ShoplistEvent is a change to a shoplist pushed to the members watching it. Every event matches an entry of the activity log.
+type ShoplistEvent struct {
+	ID           int
+	ShopListID   int
+	ActorID      string
+	Action       string
+	ItemID       *int
+	BeforeValues string
+	AfterValues  string
+	CreatedAt    time.Time
+}
+
+// EndsSubscription reports whether the user can no longer watch the shoplist after the event
+func (e ShoplistEvent) EndsSubscription(userID string) bool {
+	switch e.Action {
+	case ActivityShoplistDeleted:
+		return true
+	case ActivityMemberLeft:
+		return e.ActorID == userID
+	case ActivityMemberRemoved:
+		var before struct {
+			MemberID string `json:"member_id"`
+		}
+		if err := json.Unmarshal([]byte(e.BeforeValues), &before); err != nil {
+			return false
+		}
+		return before.MemberID == userID
+	}
+	return false
+}
+
+// ShoplistEventBroker delivers shoplist events to the subscribers of a shoplist. The in-process broker only reaches
+// subscribers of the same instance, running several instances needs a broker backed by a shared message bus.
+type ShoplistEventBroker interface {
+	// Publish delivers an event to the current subscribers of its shoplist without blocking
+	Publish(event ShoplistEvent)
+	// Subscribe returns the events of a shoplist and a function to stop the subscription.
+	// The channel is closed when the subscription stops.
+	Subscribe(shoplistID int) (<-chan ShoplistEvent, func())
+}
+
+// DefaultShoplistEventBuffer is how many events a subscriber can fall behind before it is dropped
+const DefaultShoplistEventBuffer = 64
+
+// InProcessShoplistEventBroker delivers shoplist events to subscribers in the same process
+type InProcessShoplistEventBroker struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[int]map[chan ShoplistEvent]struct{}
+}
+
+func NewInProcessShoplistEventBroker(bufferSize int) *InProcessShoplistEventBroker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultShoplistEventBuffer
+	}
+	return &InProcessShoplistEventBroker{
+		bufferSize:  bufferSize,
+		subscribers: make(map[int]map[chan ShoplistEvent]struct{}),
+	}
+}
+
+func (b *InProcessShoplistEventBroker) Publish(event ShoplistEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers[event.ShopListID] {
+		select {
+		case subscriber <- event:
+		default:
+			// A subscriber that cannot keep up would miss events, drop it so that the client reconnects and refetches
+			b.removeLocked(event.ShopListID, subscriber)
+		}
+	}
+}
+
+func (b *InProcessShoplistEventBroker) Subscribe(shoplistID int) (<-chan ShoplistEvent, func()) {
+	subscriber := make(chan ShoplistEvent, b.bufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[shoplistID] == nil {
+		b.subscribers[shoplistID] = make(map[chan ShoplistEvent]struct{})
+	}
+	b.subscribers[shoplistID][subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(shoplistID, subscriber)
+	}
+
+	return subscriber, unsubscribe
+}
+
+// removeLocked closes and removes a subscriber if it is still subscribed. The caller must hold the lock.
+func (b *InProcessShoplistEventBroker) removeLocked(shoplistID int, subscriber chan ShoplistEvent) {
+	if _, exists := b.subscribers[shoplistID][subscriber]; !exists {
+		return
+	}
+
+	delete(b.subscribers[shoplistID], subscriber)
+	if len(b.subscribers[shoplistID]) == 0 {
+		delete(b.subscribers, shoplistID)
+	}
+	close(subscriber)
+}
+
+// SubscribeShoplistEvents subscribes a member to the events of a shoplist
+func (b *ShoplistBiz) SubscribeShoplistEvents(ctx context.Context, userID string, shoplistID int) (<-chan ShoplistEvent, func(), *ShoplistError) {
+	if _, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID); !isMember {
+		return nil, nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	events, unsubscribe := b.eventBroker.Subscribe(shoplistID)
+	return events, unsubscribe, nil
+}
+
+type pendingShoplistEventsKey struct{}
+
+// pendingShoplistEvents holds the events recorded in a transaction until it commits
+type pendingShoplistEvents struct {
+	events []ShoplistEvent
+}
+
+// collectShoplistEvents returns a context that collects the events recorded by transactions started with it
+func collectShoplistEvents(ctx context.Context) (context.Context, *pendingShoplistEvents) {
+	pending := &pendingShoplistEvents{}
+	return context.WithValue(ctx, pendingShoplistEventsKey{}, pending), pending
+}
+
+// addPendingShoplistEvent adds the event of an activity to the events collected by the transaction, if any
+// gormDB Context already established before calling this function
+func addPendingShoplistEvent(tx *gorm.DB, activity db.ShoplistActivity) {
+	pending, ok := tx.Statement.Context.Value(pendingShoplistEventsKey{}).(*pendingShoplistEvents)
+	if !ok {
+		return
+	}
+
+	pending.events = append(pending.events, ShoplistEvent{
+		ID:           activity.ID,
+		ShopListID:   activity.ShopListID,
+		ActorID:      activity.ActorID,
+		Action:       activity.Action,
+		ItemID:       activity.ItemID,
+		BeforeValues: activity.BeforeValues,
+		AfterValues:  activity.AfterValues,
+		CreatedAt:    activity.CreatedAt,
+	})
+}
+
+// publishShoplistEvents publishes the events collected by a committed transaction
+func (b *ShoplistBiz) publishShoplistEvents(pending *pendingShoplistEvents) {
+	if b.eventBroker == nil {
+		return
+	}
+
+	for _, event := range pending.events {
+		b.eventBroker.Publish(event)
+	}
+}
+
+// transaction runs fn in a transaction and publishes the events it recorded once the transaction commits
+func (b *ShoplistBiz) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	ctx, pending := collectShoplistEvents(ctx)
+	if err := b.dbPool.GetDB().WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+
+	b.publishShoplistEvents(pending)
+	return nil
+}
@@ -0,0 +1,59 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInProcessShoplistEventBroker(t *testing.T) {
+	broker := NewInProcessShoplistEventBroker(2)
+
+	events1, unsubscribe1 := broker.Subscribe(1)
+	events2, unsubscribe2 := broker.Subscribe(2)
+	defer unsubscribe2()
+
+	// Events only reach the subscribers of their shoplist
+	broker.Publish(ShoplistEvent{ID: 1, ShopListID: 1, Action: ActivityItemAdded})
+	assert.Equal(t, 1, (<-events1).ID)
+	assert.Equal(t, 0, len(events2))
+
+	// A subscriber that falls behind is dropped
+	broker.Publish(ShoplistEvent{ID: 2, ShopListID: 1})
+	broker.Publish(ShoplistEvent{ID: 3, ShopListID: 1})
+	broker.Publish(ShoplistEvent{ID: 4, ShopListID: 1})
+	assert.Equal(t, 2, (<-events1).ID)
+	assert.Equal(t, 3, (<-events1).ID)
+	_, ok := <-events1
+	assert.False(t, ok)
+
+	// Unsubscribing a dropped subscriber is safe, and unsubscribing closes the channel
+	unsubscribe1()
+	events3, unsubscribe3 := broker.Subscribe(1)
+	unsubscribe3()
+	unsubscribe3()
+	_, ok = <-events3
+	assert.False(t, ok)
+}
+
+func TestShoplistEventEndsSubscription(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    ShoplistEvent
+		userID   string
+		expected bool
+	}{
+		{"item change", ShoplistEvent{Action: ActivityItemAdded, ActorID: "user1"}, "user1", false},
+		{"shoplist deleted", ShoplistEvent{Action: ActivityShoplistDeleted, ActorID: "user2"}, "user1", true},
+		{"user left", ShoplistEvent{Action: ActivityMemberLeft, ActorID: "user1"}, "user1", true},
+		{"other member left", ShoplistEvent{Action: ActivityMemberLeft, ActorID: "user2"}, "user1", false},
+		{"user removed", ShoplistEvent{Action: ActivityMemberRemoved, ActorID: "user2", BeforeValues: `{"member_id":"user1"}`}, "user1", true},
+		{"other member removed", ShoplistEvent{Action: ActivityMemberRemoved, ActorID: "user2", BeforeValues: `{"member_id":"user3"}`}, "user1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.event.EndsSubscription(tt.userID))
+		})
+	}
+}
@@ -1,15 +1,24 @@
 package bizshoplist
 
-import "github.com/kdjuwidja/aishoppercommon/db"
+import (
+	"time"
+
+	"github.com/kdjuwidja/aishoppercommon/db"
+)
 
 // ShoplistBiz dependencies
 type ShoplistBiz struct {
-	dbPool db.MySQLConnectionPool
+	dbPool      db.MySQLConnectionPool
+	eventBroker ShoplistEventBroker
+	// trashRetention is how long deleted shoplists and items are kept, 0 when the trash is never purged
+	trashRetention time.Duration
 }
 
 // Dependency Injection for ShoplistBiz
-func InitializeShoplistBiz(dbPool db.MySQLConnectionPool) *ShoplistBiz {
+func InitializeShoplistBiz(dbPool db.MySQLConnectionPool, eventBroker ShoplistEventBroker, trashRetention time.Duration) *ShoplistBiz {
 	return &ShoplistBiz{
-		dbPool: dbPool,
+		dbPool:         dbPool,
+		eventBroker:    eventBroker,
+		trashRetention: trashRetention,
 	}
 }
@@ -0,0 +1,231 @@
+package bizshoplist
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// Invitation statuses
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+// InviteToShoplist creates a pending invitation for a user to join a shoplist. Only the owner can invite users.
+// Inviting a user who already has a pending invitation updates the role of that invitation.
+func (b *ShoplistBiz) InviteToShoplist(ctx context.Context, userID string, shoplistID int, inviteeID string, role string) (*db.ShoplistInvitation, *ShoplistError) {
+	if role == "" {
+		role = MemberRoleEditor
+	}
+	if !IsValidAssignableMemberRole(role) {
+		return nil, NewShoplistError(ShoplistInvalidMemberRole, "Role must be editor or viewer.")
+	}
+
+	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
+	if shopListErr != nil {
+		return nil, shopListErr
+	}
+
+	// check if user is a member
+	if _, exists := shopListData.Members[userID]; !exists {
+		return nil, NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+	}
+
+	// check if user is the owner
+	if shopListData.OwnerID != userID {
+		return nil, NewShoplistError(ShoplistNotOwner, "Only the owner can invite users.")
+	}
+
+	// check if the invitee is already a member
+	if _, exists := shopListData.Members[inviteeID]; exists {
+		return nil, NewShoplistError(ShoplistAlreadyMember, "User is already a member of the shoplist.")
+	}
+
+	// check if the invitee exists
+	var invitee db.User
+	if err := b.dbPool.GetDB().WithContext(ctx).Where("id = ?", inviteeID).First(&invitee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, NewShoplistError(ShoplistInviteeNotFound, "Invitee not found.")
+		}
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to check invitee.")
+	}
+
+	var invitation db.ShoplistInvitation
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("shop_list_id = ? AND invitee_id = ? AND status = ?", shoplistID, inviteeID, InvitationStatusPending).First(&invitation).Error
+		if err == nil {
+			err = tx.Model(&invitation).Updates(map[string]interface{}{"role": role, "invited_by": userID}).Error
+		} else if err == gorm.ErrRecordNotFound {
+			invitation = db.ShoplistInvitation{
+				ShopListID: shoplistID,
+				InviteeID:  inviteeID,
+				InvitedBy:  userID,
+				Role:       role,
+				Status:     InvitationStatusPending,
+			}
+			err = tx.Create(&invitation).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityInvitationSent, 0, nil, activityValues{"invitee_id": inviteeID, "role": role})
+	}); err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to create invitation.")
+	}
+
+	return &invitation, nil
+}
+
+// GetShoplistInvitations returns the pending invitations of a user to active shoplists
+func (b *ShoplistBiz) GetShoplistInvitations(ctx context.Context, userID string) ([]ShoplistInvitation, *ShoplistError) {
+	invitations := make([]ShoplistInvitation, 0)
+	err := b.dbPool.GetDB().WithContext(ctx).Raw(`
+		SELECT shoplist_invitations.id as id, shoplist_invitations.shop_list_id as shop_list_id, shoplists.name as shop_list_name,
+			shoplist_invitations.invited_by as invited_by, users.nickname as invited_by_nickname,
+			shoplist_invitations.role as role, shoplist_invitations.created_at as invited_at
+		FROM shoplist_invitations
+		JOIN shoplists ON shoplist_invitations.shop_list_id = shoplists.id AND shoplists.deleted_at IS NULL
+		LEFT JOIN users ON shoplist_invitations.invited_by = users.id
+		WHERE shoplist_invitations.invitee_id = ? AND shoplist_invitations.status = ? AND shoplist_invitations.deleted_at IS NULL
+		ORDER BY shoplist_invitations.created_at DESC, shoplist_invitations.id`, userID, InvitationStatusPending).Scan(&invitations).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get invitations.")
+	}
+
+	return invitations, nil
+}
+
+// getPendingInvitees returns the users with a pending invitation to a shoplist
+func (b *ShoplistBiz) getPendingInvitees(ctx context.Context, shoplistID int) ([]ShoplistInvitee, error) {
+	invitees := make([]ShoplistInvitee, 0)
+	err := b.dbPool.GetDB().WithContext(ctx).Raw(`
+		SELECT shoplist_invitations.id as invitation_id, shoplist_invitations.invitee_id as id, users.nickname as nickname,
+			shoplist_invitations.role as role, shoplist_invitations.created_at as invited_at
+		FROM shoplist_invitations
+		LEFT JOIN users ON shoplist_invitations.invitee_id = users.id
+		WHERE shoplist_invitations.shop_list_id = ? AND shoplist_invitations.status = ? AND shoplist_invitations.deleted_at IS NULL
+		ORDER BY shoplist_invitations.created_at, shoplist_invitations.id`, shoplistID, InvitationStatusPending).Scan(&invitees).Error
+	return invitees, err
+}
+
+// AcceptShoplistInvitation adds the invitee to the shoplist with the role of the invitation.
+// An invitation from the current owner lifts any ban on the invitee, a banned invitee cannot accept any other invitation.
+func (b *ShoplistBiz) AcceptShoplistInvitation(ctx context.Context, userID string, invitationID int) *ShoplistError {
+	var acceptErr *ShoplistError
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		invitation, invitationErr := lockPendingInvitation(tx, userID, invitationID)
+		if invitationErr != nil {
+			acceptErr = invitationErr
+			return acceptErr
+		}
+
+		// The shoplist may have been deleted after the invitation was sent
+		var shoplist db.Shoplist
+		if err := tx.Where("id = ?", invitation.ShopListID).First(&shoplist).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				acceptErr = NewShoplistError(ShoplistInvitationNotFound, "Invitation not found.")
+				return acceptErr
+			}
+			return err
+		}
+
+		// An invitation sent before the ownership changed does not override a ban of the current owner
+		liftBan := invitation.InvitedBy == shoplist.OwnerID
+		if !liftBan {
+			var banCount int64
+			if err := tx.Model(&db.ShoplistBan{}).Where("shop_list_id = ? AND member_id = ?", invitation.ShopListID, userID).Count(&banCount).Error; err != nil {
+				return err
+			}
+			if banCount > 0 {
+				acceptErr = NewShoplistError(ShoplistMemberBanned, "User is banned from the shoplist.")
+				return acceptErr
+			}
+		}
+
+		var memberCount int64
+		if err := tx.Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", invitation.ShopListID, userID).Count(&memberCount).Error; err != nil {
+			return err
+		}
+
+		if memberCount == 0 {
+			newMember := db.ShoplistMember{
+				ShopListID: invitation.ShopListID,
+				MemberID:   userID,
+				Role:       invitation.Role,
+			}
+			if err := addShoplistMember(tx, &newMember); err != nil {
+				return err
+			}
+
+			if err := recordActivity(tx, invitation.ShopListID, userID, ActivityMemberJoined, 0, nil,
+				activityValues{"member_id": userID, "role": newMember.Role, "via": "invitation"}); err != nil {
+				return err
+			}
+		}
+
+		if liftBan {
+			if err := tx.Where("shop_list_id = ? AND member_id = ?", invitation.ShopListID, userID).Unscoped().Delete(&db.ShoplistBan{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(invitation).Update("status", InvitationStatusAccepted).Error
+	})
+
+	if acceptErr != nil {
+		return acceptErr
+	}
+	if err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to accept invitation.")
+	}
+
+	return nil
+}
+
+// DeclineShoplistInvitation declines a pending invitation
+func (b *ShoplistBiz) DeclineShoplistInvitation(ctx context.Context, userID string, invitationID int) *ShoplistError {
+	var declineErr *ShoplistError
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		invitation, invitationErr := lockPendingInvitation(tx, userID, invitationID)
+		if invitationErr != nil {
+			declineErr = invitationErr
+			return declineErr
+		}
+
+		if err := tx.Model(invitation).Update("status", InvitationStatusDeclined).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, invitation.ShopListID, userID, ActivityInvitationDeclined, 0, activityValues{"invitee_id": userID, "role": invitation.Role}, nil)
+	})
+
+	if declineErr != nil {
+		return declineErr
+	}
+	if err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to decline invitation.")
+	}
+
+	return nil
+}
+
+// lockPendingInvitation locks a pending invitation of the user so that it can only be answered once
+// gormDB Context already established before calling this function
+func lockPendingInvitation(tx *gorm.DB, userID string, invitationID int) (*db.ShoplistInvitation, *ShoplistError) {
+	var invitation db.ShoplistInvitation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND invitee_id = ? AND status = ?", invitationID, userID, InvitationStatusPending).First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, NewShoplistError(ShoplistInvitationNotFound, "Invitation not found.")
+		}
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to check invitation.")
+	}
+
+	return &invitation, nil
+}
@@ -0,0 +1,161 @@
+package bizshoplist
+
+import (
+	"strings"
+
+	bizmodels "netherealmstudio.com/m/v2/biz"
+)
+
+const (
+	ItemCategoryProduce      = "produce"
+	ItemCategoryBakery       = "bakery"
+	ItemCategoryMeatSeafood  = "meat_seafood"
+	ItemCategoryDairy        = "dairy"
+	ItemCategoryFrozen       = "frozen"
+	ItemCategoryPantry       = "pantry"
+	ItemCategorySnacks       = "snacks"
+	ItemCategoryBeverages    = "beverages"
+	ItemCategoryHousehold    = "household"
+	ItemCategoryPersonalCare = "personal_care"
+	ItemCategoryOther        = "other"
+)
+
+// itemCategoryDictionary lists the categories in the order they are displayed, roughly following a store walk,
+// together with the keywords used to auto-classify new items.
+var itemCategoryDictionary = []struct {
+	Category string
+	Keywords []string
+}{
+	{ItemCategoryProduce, []string{"apple", "banana", "orange", "lemon", "lime", "grape", "berry", "strawberry", "blueberry", "raspberry",
+		"pear", "peach", "plum", "mango", "pineapple", "melon", "watermelon", "avocado", "tomato", "potato", "onion", "garlic", "ginger",
+		"carrot", "celery", "lettuce", "spinach", "kale", "cabbage", "broccoli", "cauliflower", "cucumber", "pepper", "zucchini",
+		"mushroom", "corn", "pea", "bean sprout", "herb", "cilantro", "parsley", "basil", "fruit", "vegetable", "salad"}},
+	{ItemCategoryBakery, []string{"bread", "bagel", "bun", "roll", "baguette", "croissant", "muffin", "cake", "brownie", "pie", "tortilla", "pita", "loaf"}},
+	{ItemCategoryMeatSeafood, []string{"chicken", "beef", "pork", "lamb", "turkey", "bacon", "ham", "sausage", "steak", "ground beef",
+		"meat", "fish", "salmon", "tuna", "cod", "shrimp", "prawn", "crab", "lobster", "seafood", "tofu"}},
+	{ItemCategoryDairy, []string{"milk", "cheese", "yogurt", "yoghurt", "butter", "cream", "sour cream", "egg", "margarine", "kefir"}},
+	{ItemCategoryFrozen, []string{"frozen", "ice cream", "popsicle", "frozen pizza", "frozen vegetable", "ice"}},
+	{ItemCategoryPantry, []string{"rice", "pasta", "noodle", "flour", "sugar", "salt", "oil", "olive oil", "vinegar", "sauce", "ketchup",
+		"mustard", "mayonnaise", "cereal", "oat", "oatmeal", "soup", "can", "canned", "spice", "honey", "jam", "peanut butter", "syrup", "baking soda"}},
+	{ItemCategorySnacks, []string{"chip", "cracker", "cookie", "chocolate", "candy", "popcorn", "pretzel", "nut", "granola bar", "snack"}},
+	{ItemCategoryBeverages, []string{"water", "juice", "soda", "pop", "coffee", "tea", "beer", "wine", "drink", "sparkling water", "kombucha"}},
+	{ItemCategoryHousehold, []string{"paper towel", "toilet paper", "tissue", "detergent", "soap", "dish soap", "sponge", "trash bag",
+		"garbage bag", "foil", "plastic wrap", "bleach", "cleaner", "battery", "light bulb"}},
+	{ItemCategoryPersonalCare, []string{"shampoo", "conditioner", "toothpaste", "toothbrush", "deodorant", "lotion", "razor", "floss",
+		"body wash", "sunscreen", "diaper", "wipes"}},
+	{ItemCategoryOther, []string{}},
+}
+
+// IsValidItemCategory returns true if the category is one of the known categories
+func IsValidItemCategory(category string) bool {
+	for _, entry := range itemCategoryDictionary {
+		if entry.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// ItemCategoryOrder returns the position of a category in the display order.
+// Unknown categories are ordered with other.
+func ItemCategoryOrder(category string) int {
+	for i, entry := range itemCategoryDictionary {
+		if entry.Category == category {
+			return i
+		}
+	}
+	return len(itemCategoryDictionary) - 1
+}
+
+// ItemCategories returns all known categories in display order
+func ItemCategories() []string {
+	categories := make([]string, 0, len(itemCategoryDictionary))
+	for _, entry := range itemCategoryDictionary {
+		categories = append(categories, entry.Category)
+	}
+	return categories
+}
+
+// ClassifyItemCategory guesses the category of an item from the keywords found in its name.
+// The longest matching keyword wins so that "ice cream" is frozen rather than dairy;
+// ties go to the category that comes first in the dictionary. Items without a match are other.
+func ClassifyItemCategory(itemName string) string {
+	words := strings.Fields(strings.ToLower(itemName))
+	for i, word := range words {
+		words[i] = singularize(strings.Trim(word, ".,;:!?()\"'"))
+	}
+	normalized := " " + strings.Join(words, " ") + " "
+
+	bestCategory := ItemCategoryOther
+	bestLength := 0
+	for _, entry := range itemCategoryDictionary {
+		for _, keyword := range entry.Keywords {
+			if len(keyword) > bestLength && strings.Contains(normalized, " "+keyword+" ") {
+				bestCategory = entry.Category
+				bestLength = len(keyword)
+			}
+		}
+	}
+
+	return bestCategory
+}
+
+// itemCategoryKeywordWords holds every word used in the keywords of the dictionary
+var itemCategoryKeywordWords = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, entry := range itemCategoryDictionary {
+		for _, keyword := range entry.Keywords {
+			for _, word := range strings.Fields(keyword) {
+				words[word] = true
+			}
+		}
+	}
+	return words
+}()
+
+// singularize returns the singular form of a word that is used in a keyword, so that "apples" matches "apple".
+// English plurals are ambiguous, "cookies" is "cookie" but "berries" is "berry", so every plausible singular form is
+// tried against the keywords. Words that are not used in any keyword are returned as is.
+func singularize(word string) string {
+	if itemCategoryKeywordWords[word] || len(word) < 3 || !strings.HasSuffix(word, "s") {
+		return word
+	}
+
+	candidates := []string{strings.TrimSuffix(word, "s")}
+	if base, found := strings.CutSuffix(word, "ies"); found {
+		candidates = append(candidates, base+"ie", base+"y")
+	}
+	if base, found := strings.CutSuffix(word, "es"); found {
+		candidates = append(candidates, base)
+	}
+
+	for _, candidate := range candidates {
+		if itemCategoryKeywordWords[candidate] {
+			return candidate
+		}
+	}
+	return word
+}
+
+// GroupItemsByCategory groups items by category in display order. Items keep their relative order within a group
+// and empty categories are omitted.
+func GroupItemsByCategory(items []bizmodels.ShoplistItem) []ShoplistItemCategoryGroup {
+	buckets := make([][]bizmodels.ShoplistItem, len(itemCategoryDictionary))
+	for _, item := range items {
+		order := ItemCategoryOrder(item.Category)
+		buckets[order] = append(buckets[order], item)
+	}
+
+	groups := make([]ShoplistItemCategoryGroup, 0)
+	for i, bucket := range buckets {
+		if len(bucket) == 0 {
+			continue
+		}
+		groups = append(groups, ShoplistItemCategoryGroup{
+			Category: itemCategoryDictionary[i].Category,
+			Items:    bucket,
+		})
+	}
+
+	return groups
+}
@@ -0,0 +1,107 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bizmodels "netherealmstudio.com/m/v2/biz"
+)
+
+func TestClassifyItemCategory(t *testing.T) {
+	tests := []struct {
+		name             string
+		itemName         string
+		expectedCategory string
+	}{
+		{name: "single keyword", itemName: "Bananas", expectedCategory: ItemCategoryProduce},
+		{name: "plural ending in ies", itemName: "strawberries", expectedCategory: ItemCategoryProduce},
+		{name: "plural ending in oes", itemName: "Tomatoes", expectedCategory: ItemCategoryProduce},
+		{name: "plural of a word ending in ie", itemName: "Chocolate chip cookies", expectedCategory: ItemCategorySnacks},
+		{name: "plural cookies", itemName: "cookies", expectedCategory: ItemCategorySnacks},
+		{name: "plural brownies", itemName: "Brownies", expectedCategory: ItemCategoryBakery},
+		{name: "plural ending in ches", itemName: "peaches", expectedCategory: ItemCategoryProduce},
+		{name: "plural ending in ies to y", itemName: "AA batteries", expectedCategory: ItemCategoryHousehold},
+		{name: "keyword ending in s", itemName: "baby wipes", expectedCategory: ItemCategoryPersonalCare},
+		{name: "dairy", itemName: "2% Milk", expectedCategory: ItemCategoryDairy},
+		{name: "longest keyword wins", itemName: "Vanilla Ice Cream", expectedCategory: ItemCategoryFrozen},
+		{name: "frozen beats produce", itemName: "frozen peas", expectedCategory: ItemCategoryFrozen},
+		{name: "multi word keyword", itemName: "Toilet Paper 12 rolls", expectedCategory: ItemCategoryHousehold},
+		{name: "meat", itemName: "chicken breast", expectedCategory: ItemCategoryMeatSeafood},
+		{name: "no keyword", itemName: "Test Item", expectedCategory: ItemCategoryOther},
+		{name: "empty name", itemName: "", expectedCategory: ItemCategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCategory, ClassifyItemCategory(tt.itemName))
+		})
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	assert.Equal(t, "cookie", singularize("cookies"))
+	assert.Equal(t, "berry", singularize("berries"))
+	assert.Equal(t, "potato", singularize("potatoes"))
+	assert.Equal(t, "apple", singularize("apples"))
+	// Words that are not keywords are not cut short
+	assert.Equal(t, "shoes", singularize("shoes"))
+	assert.Equal(t, "glass", singularize("glass"))
+}
+
+func TestItemCategoryOrder(t *testing.T) {
+	categories := ItemCategories()
+	for i, category := range categories {
+		assert.Equal(t, i, ItemCategoryOrder(category))
+		assert.True(t, IsValidItemCategory(category))
+	}
+
+	assert.Equal(t, ItemCategoryOther, categories[len(categories)-1])
+	assert.Equal(t, ItemCategoryOrder(ItemCategoryOther), ItemCategoryOrder("unknown"))
+	assert.False(t, IsValidItemCategory("unknown"))
+}
+
+func TestGroupItemsByCategory(t *testing.T) {
+	items := []bizmodels.ShoplistItem{
+		{ID: 1, ItemName: "Milk", Category: ItemCategoryDairy},
+		{ID: 2, ItemName: "Test Item", Category: ItemCategoryOther},
+		{ID: 3, ItemName: "Apples", Category: ItemCategoryProduce},
+		{ID: 4, ItemName: "Cheese", Category: ItemCategoryDairy},
+		{ID: 5, ItemName: "Legacy", Category: ""},
+	}
+
+	groups := GroupItemsByCategory(items)
+	assert.Equal(t, 3, len(groups))
+
+	assert.Equal(t, ItemCategoryProduce, groups[0].Category)
+	assert.Equal(t, []int{3}, itemIDs(groups[0].Items))
+
+	assert.Equal(t, ItemCategoryDairy, groups[1].Category)
+	assert.Equal(t, []int{1, 4}, itemIDs(groups[1].Items))
+
+	assert.Equal(t, ItemCategoryOther, groups[2].Category)
+	assert.Equal(t, []int{2, 5}, itemIDs(groups[2].Items))
+}
+
+func TestNewShoplistItemName(t *testing.T) {
+	// The name is trimmed before the category is classified from it
+	item, err := newShoplistItem(1, "  milk  ", "", "", "", 0, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "milk", item.ItemName)
+	assert.Equal(t, ItemCategoryDairy, item.Category)
+
+	// A name of only spaces is rejected
+	for _, itemName := range []string{"", "   ", "\t\n"} {
+		_, err = newShoplistItem(1, itemName, "", "", "", 0, "", "")
+		if assert.NotNil(t, err) {
+			assert.Equal(t, ShoplistItemNameEmpty, err.ErrCode)
+		}
+	}
+}
+
+func itemIDs(items []bizmodels.ShoplistItem) []int {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
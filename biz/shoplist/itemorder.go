@@ -0,0 +1,128 @@
+package bizshoplist
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// lockShoplist takes a row lock on the shoplist for the rest of the transaction
+// gormDB Context already established before calling this function
+func lockShoplist(tx *gorm.DB, shoplistID int) error {
+	var shoplist db.Shoplist
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", shoplistID).First(&shoplist).Error
+}
+
+// nextItemPosition locks the shoplist and returns the position after the last item of the list
+// gormDB Context already established before calling this function
+func nextItemPosition(tx *gorm.DB, shoplistID int) (int, error) {
+	if err := lockShoplist(tx, shoplistID); err != nil {
+		return 0, err
+	}
+
+	var maxPosition int
+	if err := tx.Model(&db.ShoplistItem{}).Where("shop_list_id = ?", shoplistID).Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+		return 0, err
+	}
+
+	return maxPosition + 1, nil
+}
+
+// mergeItemOrder applies the requested order to the current items of a list.
+// Items that are not part of the request, e.g. added by another member after the client loaded the list,
+// keep their relative order and are placed after the requested items.
+func mergeItemOrder(currentIDs []int, requestedIDs []int) ([]int, *ShoplistError) {
+	current := make(map[int]bool, len(currentIDs))
+	for _, id := range currentIDs {
+		current[id] = true
+	}
+
+	seen := make(map[int]bool, len(requestedIDs))
+	order := make([]int, 0, len(currentIDs))
+	for _, id := range requestedIDs {
+		if seen[id] {
+			return nil, NewShoplistError(ShoplistItemInvalidOrder, "Item IDs must not contain duplicates.")
+		}
+		if !current[id] {
+			return nil, NewShoplistError(ShoplistItemNotFound, "Item not found.")
+		}
+		seen[id] = true
+		order = append(order, id)
+	}
+
+	for _, id := range currentIDs {
+		if !seen[id] {
+			order = append(order, id)
+		}
+	}
+
+	return order, nil
+}
+
+// ReorderShoplistItems persists a new order of the items in a shoplist and returns the resulting order of item IDs
+func (b *ShoplistBiz) ReorderShoplistItems(ctx context.Context, userID string, shoplistID int, itemIDs []int) ([]int, *ShoplistError) {
+	if len(itemIDs) == 0 {
+		return nil, NewShoplistError(ShoplistItemInvalidOrder, "Item IDs are required.")
+	}
+
+	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
+	if shopListErr != nil {
+		return nil, shopListErr
+	}
+
+	// check if user is a member
+	if _, exists := shopListData.Members[userID]; !exists {
+		return nil, NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+	}
+
+	// check if user can edit items
+	if !canEditItems(shopListData.Members[userID].Role) {
+		return nil, NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	var order []int
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		// Lock the shoplist so that items added concurrently are either fully before or after the reorder
+		if err := lockShoplist(tx, shoplistID); err != nil {
+			return err
+		}
+
+		var currentIDs []int
+		if err := tx.Model(&db.ShoplistItem{}).Where("shop_list_id = ?", shoplistID).Order("position, id").Pluck("id", &currentIDs).Error; err != nil {
+			return err
+		}
+
+		var orderErr *ShoplistError
+		order, orderErr = mergeItemOrder(currentIDs, itemIDs)
+		if orderErr != nil {
+			return orderErr
+		}
+
+		// Update all positions in a single statement
+		var caseSQL strings.Builder
+		args := make([]interface{}, 0, len(order)*2)
+		caseSQL.WriteString("CASE id")
+		for i, id := range order {
+			caseSQL.WriteString(" WHEN ? THEN ?")
+			args = append(args, id, i+1)
+		}
+		caseSQL.WriteString(" END")
+
+		if err := tx.Model(&db.ShoplistItem{}).Where("shop_list_id = ? AND id IN ?", shoplistID, order).
+			Update("position", gorm.Expr(caseSQL.String(), args...)).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityItemsReordered, 0, activityValues{"item_ids": currentIDs}, activityValues{"item_ids": order})
+	}); err != nil {
+		if shoplistErr, ok := err.(*ShoplistError); ok {
+			return nil, shoplistErr
+		}
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to reorder items.")
+	}
+
+	return order, nil
+}
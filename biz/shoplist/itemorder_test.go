@@ -0,0 +1,55 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeItemOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentIDs    []int
+		requestedIDs  []int
+		expectedOrder []int
+		expectedError string
+	}{
+		{
+			name:          "full reorder",
+			currentIDs:    []int{1, 2, 3},
+			requestedIDs:  []int{3, 1, 2},
+			expectedOrder: []int{3, 1, 2},
+		},
+		{
+			name:          "items added concurrently are appended",
+			currentIDs:    []int{1, 2, 3, 4, 5},
+			requestedIDs:  []int{2, 1, 3},
+			expectedOrder: []int{2, 1, 3, 4, 5},
+		},
+		{
+			name:          "duplicate item",
+			currentIDs:    []int{1, 2, 3},
+			requestedIDs:  []int{1, 1, 2},
+			expectedError: ShoplistItemInvalidOrder,
+		},
+		{
+			name:          "item not in list",
+			currentIDs:    []int{1, 2, 3},
+			requestedIDs:  []int{1, 9},
+			expectedError: ShoplistItemNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := mergeItemOrder(tt.currentIDs, tt.requestedIDs)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.ErrCode)
+				assert.Nil(t, order)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedOrder, order)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package bizshoplist
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParsedItem holds the fields extracted from the free text of an item
+type ParsedItem struct {
+	ItemName  string
+	BrandName string
+	// Quantity is 0 when the text has no quantity
+	Quantity float64
+	// Unit is one of the known units, empty when the text has no unit
+	Unit     string
+	Category string
+}
+
+// itemUnitAliases maps the lower-cased spellings of units found in free text to a known unit and the factor that
+// converts a quantity to it. Imperial weights are converted to grams.
+var itemUnitAliases = map[string]struct {
+	Unit   string
+	Factor float64
+}{
+	"g": {"g", 1}, "gr": {"g", 1}, "gram": {"g", 1}, "grams": {"g", 1},
+	"kg": {"kg", 1}, "kgs": {"kg", 1}, "kilo": {"kg", 1}, "kilos": {"kg", 1}, "kilogram": {"kg", 1}, "kilograms": {"kg", 1},
+	"ml": {"ml", 1}, "millilitre": {"ml", 1}, "millilitres": {"ml", 1}, "milliliter": {"ml", 1}, "milliliters": {"ml", 1},
+	"l": {"L", 1}, "litre": {"L", 1}, "litres": {"L", 1}, "liter": {"L", 1}, "liters": {"L", 1},
+	"lb": {"g", 453.592}, "lbs": {"g", 453.592}, "pound": {"g", 453.592}, "pounds": {"g", 453.592},
+	"oz": {"g", 28.3495}, "ounce": {"g", 28.3495}, "ounces": {"g", 28.3495},
+	"each": {"each", 1}, "ea": {"each", 1}, "pc": {"each", 1}, "pcs": {"each", 1}, "piece": {"each", 1}, "pieces": {"each", 1},
+	"dozen": {"each", 12}, "doz": {"each", 12},
+	"pack": {"pack", 1}, "packs": {"pack", 1}, "pk": {"pack", 1},
+	"pkg": {"pack", 1}, "pkgs": {"pack", 1}, "package": {"pack", 1}, "packages": {"pack", 1},
+}
+
+// knownItemBrands lists the brands recognized in free text, spelled as they are stored
+var knownItemBrands = []string{
+	"Maple Leaf", "Schneiders", "Olymel", "President's Choice", "No Name", "Compliments", "Irresistibles",
+	"Great Value", "Kirkland Signature", "Kraft", "Heinz", "Campbell's", "Kellogg's", "General Mills", "Quaker",
+	"Nestle", "Nescafe", "Coca-Cola", "Pepsi", "Tropicana", "Minute Maid", "Oasis", "Natrel", "Lactantia", "Neilson",
+	"Sealtest", "Dairyland", "Gay Lea", "Black Diamond", "Cracker Barrel", "Philadelphia", "Danone", "Activia",
+	"Oikos", "Yoplait", "Liberte", "Silk", "Becel", "Burnbrae Farms", "Dempster's", "Wonder", "Villaggio",
+	"Ben's Original", "Barilla", "Catelli", "Prego", "Ragu", "Hellmann's", "French's", "Clover Leaf", "Gold Seal",
+	"McCain", "Delissio", "Dole", "Del Monte", "Sun-Rype", "Christie", "Oreo", "Lay's", "Doritos", "Ruffles",
+	"Lipton", "Tetley", "Red Rose", "Folgers", "Maxwell House", "Tim Hortons", "Starbucks", "Tide", "Charmin",
+	"Cottonelle", "Royale", "Bounty", "Cascade", "Dawn", "Palmolive", "Lysol", "Clorox", "Glad", "Ziploc", "Colgate",
+	"Crest", "Dove", "Pampers", "Huggies",
+}
+
+// itemQuantityPattern matches a number or a fraction, optionally followed by a unit or x, e.g. 2, 1.5, 1/2, 500g, 2x
+var itemQuantityPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?|\d+/\d+)([a-z]*)$`)
+
+// ParseItemText extracts the quantity, unit, brand and name of an item from free text such as
+// "2 lbs Maple Leaf chicken breast". The quantity is read at the start of the text, or at its end when the text does
+// not start with one and the quantity at the end has a unit. The brand is the longest known brand found in the text.
+// The rest of the text is the name, and the category is classified from it. Text that cannot be parsed is kept as the
+// name.
+func ParseItemText(text string) ParsedItem {
+	words := strings.Fields(text)
+
+	quantity, unit, words := parseLeadingQuantity(words)
+	if quantity == 0 && unit == "" {
+		quantity, unit, words = parseTrailingQuantity(words)
+	}
+
+	brandName, words := extractItemBrand(words)
+
+	// "2 lbs of chicken" reads as chicken
+	if len(words) > 1 && strings.EqualFold(words[0], "of") {
+		words = words[1:]
+	}
+
+	itemName := strings.Join(words, " ")
+	if itemName == "" {
+		// A brand alone names the item
+		itemName, brandName = brandName, ""
+	}
+
+	return ParsedItem{
+		ItemName:  itemName,
+		BrandName: brandName,
+		Quantity:  quantity,
+		Unit:      unit,
+		Category:  ClassifyItemCategory(itemName),
+	}
+}
+
+// parseLeadingQuantity reads a quantity and unit at the start of the words, as in "2 lbs", "2lbs", "2 x" or "500g",
+// and returns the remaining words
+func parseLeadingQuantity(words []string) (float64, string, []string) {
+	if len(words) < 2 {
+		return 0, "", words
+	}
+
+	quantity, unit, ok := parseQuantityWord(words[0])
+	if !ok {
+		return 0, "", words
+	}
+	rest := words[1:]
+
+	if unit == "" && len(rest) > 1 {
+		if strings.EqualFold(rest[0], "x") {
+			rest = rest[1:]
+		} else if alias, found := itemUnitAliases[strings.ToLower(rest[0])]; found {
+			quantity, unit = convertItemQuantity(quantity, alias.Unit, alias.Factor)
+			rest = rest[1:]
+		}
+	}
+
+	return quantity, unit, rest
+}
+
+// parseTrailingQuantity reads a quantity and unit at the end of the words, as in "milk 2L" or "chicken breast 2 lbs",
+// and returns the remaining words
+func parseTrailingQuantity(words []string) (float64, string, []string) {
+	if len(words) < 2 {
+		return 0, "", words
+	}
+
+	// A number alone at the end is often part of the name, as in "diapers size 4"
+	if quantity, unit, ok := parseQuantityWord(words[len(words)-1]); ok {
+		if unit == "" {
+			return 0, "", words
+		}
+		return quantity, unit, words[:len(words)-1]
+	}
+
+	// The unit follows the number
+	if len(words) < 3 {
+		return 0, "", words
+	}
+	alias, found := itemUnitAliases[strings.ToLower(words[len(words)-1])]
+	if !found {
+		return 0, "", words
+	}
+	quantity, unit, ok := parseQuantityWord(words[len(words)-2])
+	if !ok || unit != "" {
+		return 0, "", words
+	}
+	quantity, unit = convertItemQuantity(quantity, alias.Unit, alias.Factor)
+	return quantity, unit, words[:len(words)-2]
+}
+
+// parseQuantityWord parses a number or fraction with an optional attached unit or x. Words such as 7up are not
+// quantities.
+func parseQuantityWord(word string) (float64, string, bool) {
+	match := itemQuantityPattern.FindStringSubmatch(strings.ToLower(word))
+	if match == nil {
+		return 0, "", false
+	}
+
+	var quantity float64
+	if numerator, denominator, isFraction := strings.Cut(match[1], "/"); isFraction {
+		n, _ := strconv.ParseFloat(numerator, 64)
+		d, _ := strconv.ParseFloat(denominator, 64)
+		if d == 0 {
+			return 0, "", false
+		}
+		quantity = n / d
+	} else {
+		quantity, _ = strconv.ParseFloat(match[1], 64)
+	}
+	if quantity <= 0 {
+		return 0, "", false
+	}
+
+	switch suffix := match[2]; suffix {
+	case "", "x":
+		return quantity, "", true
+	default:
+		alias, found := itemUnitAliases[suffix]
+		if !found {
+			return 0, "", false
+		}
+		quantity, unit := convertItemQuantity(quantity, alias.Unit, alias.Factor)
+		return quantity, unit, true
+	}
+}
+
+// convertItemQuantity applies the conversion factor of a unit. Converted quantities are rounded to whole units, other
+// quantities are kept as typed.
+func convertItemQuantity(quantity float64, unit string, factor float64) (float64, string) {
+	if factor == 1 {
+		return quantity, unit
+	}
+	return math.Round(quantity * factor), unit
+}
+
+// extractItemBrand finds the longest known brand in the words, ignoring case and apostrophes, and returns it with the
+// remaining words
+func extractItemBrand(words []string) (string, []string) {
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = normalizeBrandWord(word)
+	}
+
+	bestBrand := ""
+	bestStart, bestLength := 0, 0
+	for _, brand := range knownItemBrands {
+		brandWords := strings.Fields(brand)
+		for i := range brandWords {
+			brandWords[i] = normalizeBrandWord(brandWords[i])
+		}
+		if len(brandWords) <= bestLength {
+			continue
+		}
+
+		for start := 0; start+len(brandWords) <= len(normalized); start++ {
+			if equalWords(normalized[start:start+len(brandWords)], brandWords) {
+				bestBrand, bestStart, bestLength = brand, start, len(brandWords)
+				break
+			}
+		}
+	}
+	if bestBrand == "" {
+		return "", words
+	}
+
+	rest := make([]string, 0, len(words)-bestLength)
+	rest = append(rest, words[:bestStart]...)
+	rest = append(rest, words[bestStart+bestLength:]...)
+	return bestBrand, rest
+}
+
+// normalizeBrandWord lower-cases a word and drops its apostrophes so that "presidents" matches "President's"
+func normalizeBrandWord(word string) string {
+	return strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(word))
+}
+
+func equalWords(a []string, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseItemText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected ParsedItem
+	}{
+		{"2 lbs Maple Leaf chicken breast", ParsedItem{ItemName: "chicken breast", BrandName: "Maple Leaf", Quantity: 907, Unit: "g", Category: ItemCategoryMeatSeafood}},
+		{"500g pasta", ParsedItem{ItemName: "pasta", Quantity: 500, Unit: "g", Category: ItemCategoryPantry}},
+		{"1.5 L natrel milk", ParsedItem{ItemName: "milk", BrandName: "Natrel", Quantity: 1.5, Unit: "L", Category: ItemCategoryDairy}},
+		{"1/2 kg of apples", ParsedItem{ItemName: "apples", Quantity: 0.5, Unit: "kg", Category: ItemCategoryProduce}},
+		{"3 x bananas", ParsedItem{ItemName: "bananas", Quantity: 3, Category: ItemCategoryProduce}},
+		{"2x Presidents Choice bagels", ParsedItem{ItemName: "bagels", BrandName: "President's Choice", Quantity: 2, Category: ItemCategoryBakery}},
+		{"2 dozen eggs", ParsedItem{ItemName: "eggs", Quantity: 24, Unit: "each", Category: ItemCategoryDairy}},
+		{"Heinz ketchup 750ml", ParsedItem{ItemName: "ketchup", BrandName: "Heinz", Quantity: 750, Unit: "ml", Category: ItemCategoryPantry}},
+		{"chicken breast 2 lbs", ParsedItem{ItemName: "chicken breast", Quantity: 907, Unit: "g", Category: ItemCategoryMeatSeafood}},
+		{"Huggies diapers size 4", ParsedItem{ItemName: "diapers size 4", BrandName: "Huggies", Category: ItemCategoryPersonalCare}},
+		{"7up", ParsedItem{ItemName: "7up", Category: ItemCategoryOther}},
+		{"Kraft", ParsedItem{ItemName: "Kraft", Category: ItemCategoryOther}},
+		{"  milk  ", ParsedItem{ItemName: "milk", Category: ItemCategoryDairy}},
+		{"", ParsedItem{Category: ItemCategoryOther}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ParseItemText(test.text), test.text)
+	}
+}
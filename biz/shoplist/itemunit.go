@@ -0,0 +1,39 @@
+package bizshoplist
+
+import "strings"
+
+// knownItemUnits maps the lower-cased form of every accepted unit to its canonical spelling
+var knownItemUnits = map[string]string{
+	"g":    "g",
+	"kg":   "kg",
+	"ml":   "ml",
+	"l":    "L",
+	"each": "each",
+	"pack": "pack",
+}
+
+// NormalizeItemUnit returns the canonical spelling of a unit and whether it is a known unit.
+// An empty unit is valid and means the item has no unit.
+func NormalizeItemUnit(unit string) (string, bool) {
+	unit = strings.TrimSpace(unit)
+	if unit == "" {
+		return "", true
+	}
+
+	canonical, ok := knownItemUnits[strings.ToLower(unit)]
+	return canonical, ok
+}
+
+// validateItemQuantity checks the quantity and unit of an item and returns the canonical unit
+func validateItemQuantity(quantity float64, unit string) (string, *ShoplistError) {
+	if quantity < 0 {
+		return "", NewShoplistError(ShoplistItemInvalidQuantity, "Quantity must not be negative.")
+	}
+
+	canonical, ok := NormalizeItemUnit(unit)
+	if !ok {
+		return "", NewShoplistError(ShoplistItemInvalidUnit, "Unit must be one of g, kg, ml, L, each or pack.")
+	}
+
+	return canonical, nil
+}
@@ -0,0 +1,45 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeItemUnit(t *testing.T) {
+	tests := []struct {
+		name          string
+		unit          string
+		expectedUnit  string
+		expectedValid bool
+	}{
+		{name: "empty unit", unit: "", expectedUnit: "", expectedValid: true},
+		{name: "grams", unit: "g", expectedUnit: "g", expectedValid: true},
+		{name: "upper case kilograms", unit: "KG", expectedUnit: "kg", expectedValid: true},
+		{name: "millilitres", unit: "ml", expectedUnit: "ml", expectedValid: true},
+		{name: "lower case litres", unit: "l", expectedUnit: "L", expectedValid: true},
+		{name: "each with spaces", unit: " each ", expectedUnit: "each", expectedValid: true},
+		{name: "pack", unit: "Pack", expectedUnit: "pack", expectedValid: true},
+		{name: "unknown unit", unit: "lbs", expectedUnit: "", expectedValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, valid := NormalizeItemUnit(tt.unit)
+			assert.Equal(t, tt.expectedUnit, unit)
+			assert.Equal(t, tt.expectedValid, valid)
+		})
+	}
+}
+
+func TestValidateItemQuantity(t *testing.T) {
+	unit, err := validateItemQuantity(2, "KG")
+	assert.Nil(t, err)
+	assert.Equal(t, "kg", unit)
+
+	_, err = validateItemQuantity(-1, "kg")
+	assert.Equal(t, ShoplistItemInvalidQuantity, err.ErrCode)
+
+	_, err = validateItemQuantity(1, "bushel")
+	assert.Equal(t, ShoplistItemInvalidUnit, err.ErrCode)
+}
@@ -0,0 +1,163 @@
+package bizshoplist
+
+import (
+	"context"
+	"time"
+
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// joinAttemptLimit is how many failed share code redemptions are allowed within a window before the key is locked out
+type joinAttemptLimit struct {
+	MaxFailures int
+	Window      time.Duration
+	Lockout     time.Duration
+}
+
+// Failed share code redemptions are tracked per user and per client IP. The IP limit is looser
+// because several users can share an address.
+var (
+	userJoinAttemptLimit = joinAttemptLimit{MaxFailures: 5, Window: 15 * time.Minute, Lockout: 15 * time.Minute}
+	ipJoinAttemptLimit   = joinAttemptLimit{MaxFailures: 20, Window: 15 * time.Minute, Lockout: 15 * time.Minute}
+)
+
+func userJoinAttemptKey(userID string) string {
+	return "user:" + userID
+}
+
+func ipJoinAttemptKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+// countJoinAttempt returns the attempt state after one more attempt at now, and false when the key may not attempt
+// another redemption because it is locked out or the failures and pending attempts of the window reached the limit.
+// An attempt outside the current window starts a new window.
+func countJoinAttempt(attempt db.ShoplistJoinAttempt, limit joinAttemptLimit, now time.Time) (db.ShoplistJoinAttempt, bool) {
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+		return attempt, false
+	}
+
+	if attempt.WindowStart.IsZero() || now.Sub(attempt.WindowStart) >= limit.Window {
+		attempt.WindowStart = now
+		attempt.FailedCount = 0
+	}
+
+	if attempt.FailedCount >= limit.MaxFailures {
+		return attempt, false
+	}
+
+	attempt.FailedCount++
+	return attempt, true
+}
+
+// lockJoinAttempt returns the attempt state after a counted attempt failed at now. Reaching the limit locks the key.
+func lockJoinAttempt(attempt db.ShoplistJoinAttempt, limit joinAttemptLimit, now time.Time) db.ShoplistJoinAttempt {
+	if attempt.FailedCount < limit.MaxFailures {
+		return attempt
+	}
+
+	lockedUntil := now.Add(limit.Lockout)
+	attempt.LockedUntil = &lockedUntil
+	// the next failure after the lockout starts counting again
+	attempt.WindowStart = lockedUntil
+	attempt.FailedCount = 0
+	return attempt
+}
+
+// updateJoinAttempts applies update to the attempts of the user and the IP key in one transaction, with the rows
+// locked in the same order every time to avoid deadlocks. The rows are created first so that they can be locked.
+// The update returns false to leave the attempts unchanged.
+func (b *ShoplistBiz) updateJoinAttempts(ctx context.Context, userKey string, ipKey string,
+	update func(attempt db.ShoplistJoinAttempt, limit joinAttemptLimit, now time.Time) (db.ShoplistJoinAttempt, bool)) (bool, error) {
+	limits := map[string]joinAttemptLimit{ipKey: ipJoinAttemptLimit, userKey: userJoinAttemptLimit}
+	keys := []string{ipKey, userKey}
+
+	updated := true
+	err := b.dbPool.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		for _, key := range keys {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.ShoplistJoinAttempt{AttemptKey: key, WindowStart: now}).Error; err != nil {
+				return err
+			}
+		}
+
+		var attempts []db.ShoplistJoinAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("attempt_key IN ?", keys).Order("attempt_key").Find(&attempts).Error; err != nil {
+			return err
+		}
+
+		for i := range attempts {
+			attempts[i], updated = update(attempts[i], limits[attempts[i].AttemptKey], now)
+			if !updated {
+				return nil
+			}
+		}
+
+		for _, attempt := range attempts {
+			if err := tx.Model(&attempt).Select("failed_count", "window_start", "locked_until").Updates(&attempt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// reserveJoinAttempt counts an attempt against the user and the IP key before a share code is redeemed, and returns
+// false when either key may not attempt another redemption. The rows stay locked from the check to the count so that
+// concurrent redemptions cannot all pass the check before any of them is counted. Attempts that turn out not to be
+// guesses are refunded afterwards, only failed guesses can lock a key out.
+func (b *ShoplistBiz) reserveJoinAttempt(ctx context.Context, userKey string, ipKey string) (bool, error) {
+	return b.updateJoinAttempts(ctx, userKey, ipKey, countJoinAttempt)
+}
+
+// failJoinAttempt locks out the user and the IP key when a reserved attempt that failed reached their limit
+func (b *ShoplistBiz) failJoinAttempt(ctx context.Context, userKey string, ipKey string) error {
+	_, err := b.updateJoinAttempts(ctx, userKey, ipKey,
+		func(attempt db.ShoplistJoinAttempt, limit joinAttemptLimit, now time.Time) (db.ShoplistJoinAttempt, bool) {
+			return lockJoinAttempt(attempt, limit, now), true
+		})
+	return err
+}
+
+// refundJoinAttempt takes back an attempt counted against a key
+func (b *ShoplistBiz) refundJoinAttempt(ctx context.Context, key string) error {
+	return b.dbPool.GetDB().WithContext(ctx).Model(&db.ShoplistJoinAttempt{}).
+		Where("attempt_key = ? AND failed_count > 0", key).
+		Update("failed_count", gorm.Expr("failed_count - 1")).Error
+}
+
+// purgeStaleJoinAttempts removes the attempts that can no longer lead to a lockout
+func (b *ShoplistBiz) purgeStaleJoinAttempts(ctx context.Context, now time.Time) error {
+	return b.dbPool.GetDB().WithContext(ctx).Unscoped().
+		Where("window_start < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-ipJoinAttemptLimit.Window), now).
+		Delete(&db.ShoplistJoinAttempt{}).Error
+}
+
+// StartJoinAttemptPurger periodically removes the stale join attempts until the context is cancelled
+func (b *ShoplistBiz) StartJoinAttemptPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := b.purgeStaleJoinAttempts(ctx, time.Now()); err != nil {
+					logger.Errorf("StartJoinAttemptPurger: Failed to purge join attempts. Error: %s", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// resetJoinAttempts clears the failed share code redemptions of a key
+func (b *ShoplistBiz) resetJoinAttempts(ctx context.Context, key string) error {
+	return b.dbPool.GetDB().WithContext(ctx).Where("attempt_key = ?", key).Unscoped().Delete(&db.ShoplistJoinAttempt{}).Error
+}
@@ -0,0 +1,59 @@
+package bizshoplist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestCountJoinAttempt(t *testing.T) {
+	limit := joinAttemptLimit{MaxFailures: 3, Window: 10 * time.Minute, Lockout: 5 * time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Attempts within the window add up until the limit is reached, without locking the key
+	attempt := db.ShoplistJoinAttempt{WindowStart: now}
+	for i := 1; i <= 3; i++ {
+		var allowed bool
+		attempt, allowed = countJoinAttempt(attempt, limit, now.Add(time.Duration(i)*time.Minute))
+		assert.True(t, allowed)
+		assert.Equal(t, i, attempt.FailedCount)
+		assert.Nil(t, attempt.LockedUntil)
+	}
+
+	_, allowed := countJoinAttempt(attempt, limit, now.Add(4*time.Minute))
+	assert.False(t, allowed)
+
+	// An attempt after the window starts a new window
+	attempt, allowed = countJoinAttempt(attempt, limit, now.Add(11*time.Minute))
+	assert.True(t, allowed)
+	assert.Equal(t, 1, attempt.FailedCount)
+	assert.Equal(t, now.Add(11*time.Minute), attempt.WindowStart)
+
+	// A locked key cannot attempt until the lockout is over
+	lockedUntil := now.Add(5 * time.Minute)
+	attempt = db.ShoplistJoinAttempt{WindowStart: lockedUntil, LockedUntil: &lockedUntil}
+	_, allowed = countJoinAttempt(attempt, limit, now.Add(time.Minute))
+	assert.False(t, allowed)
+	attempt, allowed = countJoinAttempt(attempt, limit, now.Add(6*time.Minute))
+	assert.True(t, allowed)
+	assert.Equal(t, 1, attempt.FailedCount)
+}
+
+func TestLockJoinAttempt(t *testing.T) {
+	limit := joinAttemptLimit{MaxFailures: 3, Window: 10 * time.Minute, Lockout: 5 * time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A failure below the limit does not lock the key
+	attempt := lockJoinAttempt(db.ShoplistJoinAttempt{WindowStart: now, FailedCount: 2}, limit, now.Add(time.Minute))
+	assert.Equal(t, 2, attempt.FailedCount)
+	assert.Nil(t, attempt.LockedUntil)
+
+	// A failure on the limit locks the key and counting starts again after the lockout
+	attempt = lockJoinAttempt(db.ShoplistJoinAttempt{WindowStart: now, FailedCount: 3}, limit, now.Add(3*time.Minute))
+	assert.NotNil(t, attempt.LockedUntil)
+	assert.Equal(t, now.Add(8*time.Minute), *attempt.LockedUntil)
+	assert.Equal(t, now.Add(8*time.Minute), attempt.WindowStart)
+	assert.Equal(t, 0, attempt.FailedCount)
+}
@@ -0,0 +1,69 @@
+package bizshoplist
+
+import (
+	"context"
+)
+
+// Member roles. The owner role always follows shoplists.owner_id, the stored role only matters for the other members.
+const (
+	MemberRoleOwner  = "owner"
+	MemberRoleEditor = "editor"
+	MemberRoleViewer = "viewer"
+)
+
+// IsValidAssignableMemberRole reports whether the role can be given to a member by the owner.
+// The owner role can only be handed over through an ownership transfer.
+func IsValidAssignableMemberRole(role string) bool {
+	return role == MemberRoleEditor || role == MemberRoleViewer
+}
+
+// effectiveMemberRole resolves the role of a member from the shoplist owner and the stored role
+func effectiveMemberRole(ownerID string, memberID string, storedRole string) string {
+	if memberID == ownerID {
+		return MemberRoleOwner
+	}
+
+	// a previous owner keeps editing rights until the new owner says otherwise
+	if storedRole == "" || storedRole == MemberRoleOwner {
+		return MemberRoleEditor
+	}
+
+	return storedRole
+}
+
+// canEditItems reports whether a member with the role can add, update or remove items
+func canEditItems(role string) bool {
+	return role == MemberRoleOwner || role == MemberRoleEditor
+}
+
+// getShoplistMemberRole returns the role of a user in an active shoplist and whether the user is a member
+func (b *ShoplistBiz) getShoplistMemberRole(ctx context.Context, userID string, shoplistID int) (string, bool) {
+	var result struct {
+		OwnerID string `gorm:"column:owner_id"`
+		Role    string `gorm:"column:role"`
+	}
+
+	err := b.dbPool.GetDB().WithContext(ctx).Raw(`SELECT shoplists.owner_id, shoplist_members.role FROM shoplist_members
+		JOIN shoplists ON shoplists.id = shoplist_members.shop_list_id AND shoplists.deleted_at IS NULL
+		WHERE shoplist_members.shop_list_id = ? AND shoplist_members.member_id = ? AND shoplist_members.deleted_at IS NULL`, shoplistID, userID).Scan(&result).Error
+	if err != nil || result.OwnerID == "" {
+		return "", false
+	}
+
+	return effectiveMemberRole(result.OwnerID, userID, result.Role), true
+}
+
+// checkItemEditAccess checks that the user is a member of the shoplist that can edit items
+func (b *ShoplistBiz) checkItemEditAccess(ctx context.Context, userID string, shoplistID int) *ShoplistError {
+	role, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID)
+	if !isMember {
+		return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	// check if user can edit items
+	if !canEditItems(role) {
+		return NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	return nil
+}
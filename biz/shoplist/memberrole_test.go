@@ -0,0 +1,35 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveMemberRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		memberID   string
+		storedRole string
+		expected   string
+	}{
+		{name: "owner", memberID: "owner", storedRole: MemberRoleOwner, expected: MemberRoleOwner},
+		{name: "owner with stale role", memberID: "owner", storedRole: MemberRoleViewer, expected: MemberRoleOwner},
+		{name: "editor", memberID: "member", storedRole: MemberRoleEditor, expected: MemberRoleEditor},
+		{name: "viewer", memberID: "member", storedRole: MemberRoleViewer, expected: MemberRoleViewer},
+		{name: "previous owner", memberID: "member", storedRole: MemberRoleOwner, expected: MemberRoleEditor},
+		{name: "no role", memberID: "member", storedRole: "", expected: MemberRoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, effectiveMemberRole("owner", tt.memberID, tt.storedRole))
+		})
+	}
+}
+
+func TestCanEditItems(t *testing.T) {
+	assert.True(t, canEditItems(MemberRoleOwner))
+	assert.True(t, canEditItems(MemberRoleEditor))
+	assert.False(t, canEditItems(MemberRoleViewer))
+}
@@ -1,11 +1,21 @@
 package bizshoplist
 
+import (
+	"time"
+
+	bizmodels "netherealmstudio.com/m/v2/biz"
+	"netherealmstudio.com/m/v2/db"
+)
+
 type ShoplistItem struct {
 	ID         int
 	ShopListID int
 	ItemName   string
 	BrandName  string
 	ExtraInfo  string
+	Quantity   float64
+	Unit       string
+	Category   string
 	Thumbnail  string
 	IsBought   bool
 }
@@ -22,3 +32,203 @@ type Shoplist struct {
 	OwnerNickname string
 	Items         []ShoplistItem
 }
+
+type ShoplistItemCategoryGroup struct {
+	Category string
+	Items    []bizmodels.ShoplistItem
+}
+
+type ShoplistInvitee struct {
+	InvitationID int       `gorm:"column:invitation_id"`
+	ID           string    `gorm:"column:id"`
+	Nickname     string    `gorm:"column:nickname"`
+	Role         string    `gorm:"column:role"`
+	InvitedAt    time.Time `gorm:"column:invited_at"`
+}
+
+type ShoplistInvitation struct {
+	ID                int       `gorm:"column:id"`
+	ShopListID        int       `gorm:"column:shop_list_id"`
+	ShopListName      string    `gorm:"column:shop_list_name"`
+	InvitedBy         string    `gorm:"column:invited_by"`
+	InvitedByNickname string    `gorm:"column:invited_by_nickname"`
+	Role              string    `gorm:"column:role"`
+	InvitedAt         time.Time `gorm:"column:invited_at"`
+}
+
+type ShoplistActivity struct {
+	ID            int       `gorm:"column:id"`
+	ActorID       string    `gorm:"column:actor_id"`
+	ActorNickname string    `gorm:"column:actor_nickname"`
+	Action        string    `gorm:"column:action"`
+	ItemID        *int      `gorm:"column:item_id"`
+	BeforeValues  string    `gorm:"column:before_values"`
+	AfterValues   string    `gorm:"column:after_values"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+type TrashedShoplist struct {
+	ID        int       `gorm:"column:id"`
+	Name      string    `gorm:"column:name"`
+	DeletedAt time.Time `gorm:"column:deleted_at"`
+}
+
+type TrashedShoplistItem struct {
+	ID           int       `gorm:"column:id"`
+	ShopListID   int       `gorm:"column:shop_list_id"`
+	ShopListName string    `gorm:"column:shop_list_name"`
+	ItemName     string    `gorm:"column:item_name"`
+	BrandName    string    `gorm:"column:brand_name"`
+	DeletedAt    time.Time `gorm:"column:deleted_at"`
+}
+
+type SyncedShoplist struct {
+	ID            int       `gorm:"column:id"`
+	Name          string    `gorm:"column:name"`
+	OwnerID       string    `gorm:"column:owner_id"`
+	OwnerNickname string    `gorm:"column:owner_nickname"`
+	Version       int       `gorm:"column:version"`
+	IsTemplate    bool      `gorm:"column:is_template"`
+	UpdatedAt     time.Time `gorm:"column:updated_at"`
+}
+
+type SyncedShoplistItem struct {
+	ID         int       `gorm:"column:id"`
+	ShopListID int       `gorm:"column:shop_list_id"`
+	ItemName   string    `gorm:"column:item_name"`
+	BrandName  string    `gorm:"column:brand_name"`
+	ExtraInfo  string    `gorm:"column:extra_info"`
+	Quantity   float64   `gorm:"column:quantity"`
+	Unit       string    `gorm:"column:unit"`
+	Category   string    `gorm:"column:category"`
+	IsBought   bool      `gorm:"column:is_bought"`
+	Position   int       `gorm:"column:position"`
+	Thumbnail  string    `gorm:"column:thumbnail"`
+	Version    int       `gorm:"column:version"`
+	Recurrence string    `gorm:"column:recurrence"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+type SyncedShoplistMember struct {
+	ShopListID int    `gorm:"column:shop_list_id"`
+	MemberID   string `gorm:"column:member_id"`
+	Nickname   string `gorm:"column:nickname"`
+	Role       string `gorm:"column:role"`
+}
+
+type ShoplistItemTombstone struct {
+	ID         int `gorm:"column:id"`
+	ShopListID int `gorm:"column:shop_list_id"`
+}
+
+type ShoplistMemberTombstone struct {
+	ShopListID int    `gorm:"column:shop_list_id"`
+	MemberID   string `gorm:"column:member_id"`
+}
+
+// ShoplistSync holds the changes to the shoplists of a user since a sync cursor
+type ShoplistSync struct {
+	// Cursor is where the next sync continues from
+	Cursor string
+	// Reset is set when the changes are the full state of the user's shoplists and replace any local copy
+	Reset              bool
+	Shoplists          []SyncedShoplist
+	DeletedShoplistIDs []int
+	Items              []SyncedShoplistItem
+	DeletedItems       []ShoplistItemTombstone
+	Members            []SyncedShoplistMember
+	DeletedMembers     []ShoplistMemberTombstone
+}
+
+// OfflineOp is an operation a client queued while it was offline
+type OfflineOp struct {
+	// OpID is the client's ID of the operation, it is only passed back in the result
+	OpID      string
+	Type      string
+	Timestamp time.Time
+	// ItemID is the server ID of the item, ClientItemID the client ID of an item that was added offline
+	ItemID       int
+	ClientItemID string
+	ItemName     string
+	BrandName    string
+	ExtraInfo    string
+	Quantity     float64
+	Unit         string
+	Category     string
+	IsBought     bool
+}
+
+// OfflineOpResult is the outcome of replaying an offline operation
+type OfflineOpResult struct {
+	OpID   string
+	Status string
+	// ErrCode and Message explain why an operation was rejected
+	ErrCode      string
+	Message      string
+	ItemID       int
+	ClientItemID string
+	// Item is the item after the operation, nil when the item no longer exists
+	Item *db.ShoplistItem
+}
+
+// ShoplistItemUpdate holds the fields of an item to update, nil fields are left unchanged
+type ShoplistItemUpdate struct {
+	ItemName  *string
+	BrandName *string
+	ExtraInfo *string
+	Quantity  *float64
+	Unit      *string
+	Category  *string
+	IsBought  *bool
+	// Recurrence sets the recurrence rule of the item, an empty rule stops the item from recurring
+	Recurrence *string
+}
+
+// isEmpty reports whether the update has no fields to change
+func (u ShoplistItemUpdate) isEmpty() bool {
+	return u.ItemName == nil && u.BrandName == nil && u.ExtraInfo == nil && u.Quantity == nil &&
+		u.Unit == nil && u.Category == nil && u.IsBought == nil && u.Recurrence == nil
+}
+
+type ShoplistTemplate struct {
+	ID            int    `gorm:"column:id"`
+	Name          string `gorm:"column:name"`
+	OwnerID       string `gorm:"column:owner_id"`
+	OwnerNickname string `gorm:"column:owner_nickname"`
+	ItemCount     int    `gorm:"column:item_count"`
+}
+
+type ShoplistPurchase struct {
+	ID            int       `gorm:"column:id"`
+	ShopListID    int       `gorm:"column:shop_list_id"`
+	ShopListName  string    `gorm:"column:shop_list_name"`
+	ItemID        int       `gorm:"column:item_id"`
+	ItemName      string    `gorm:"column:item_name"`
+	BrandName     string    `gorm:"column:brand_name"`
+	Quantity      float64   `gorm:"column:quantity"`
+	Unit          string    `gorm:"column:unit"`
+	Category      string    `gorm:"column:category"`
+	BuyerID       string    `gorm:"column:buyer_id"`
+	BuyerNickname string    `gorm:"column:buyer_nickname"`
+	PurchasedAt   time.Time `gorm:"column:purchased_at"`
+}
+
+// PurchaseCount is how often an item was bought
+type PurchaseCount struct {
+	ItemName        string    `gorm:"column:item_name"`
+	BrandName       string    `gorm:"column:brand_name"`
+	Count           int       `gorm:"column:count"`
+	LastPurchasedAt time.Time `gorm:"column:last_purchased_at"`
+}
+
+// ItemSuggestion is an item used before that is suggested when adding to a shoplist
+type ItemSuggestion struct {
+	ItemName      string    `gorm:"column:item_name"`
+	BrandName     string    `gorm:"column:brand_name"`
+	Unit          string    `gorm:"column:unit"`
+	Category      string    `gorm:"column:category"`
+	ShoplistCount int       `gorm:"column:shoplist_count"`
+	OtherCount    int       `gorm:"column:other_count"`
+	LastUsedAt    time.Time `gorm:"column:last_used_at"`
+	Score         float64   `gorm:"-"`
+}
@@ -0,0 +1,284 @@
+package bizshoplist
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// Offline operation types
+const (
+	OfflineOpAddItem    = "add_item"
+	OfflineOpCheckItem  = "check_item"
+	OfflineOpRenameItem = "rename_item"
+	OfflineOpDeleteItem = "delete_item"
+)
+
+// Offline operation outcomes
+const (
+	// OfflineOpApplied means the operation was applied
+	OfflineOpApplied = "applied"
+	// OfflineOpDuplicate means the operation was applied by an earlier replay, e.g. when the client retried a batch
+	OfflineOpDuplicate = "duplicate"
+	// OfflineOpConflict means somebody else changed or deleted the item after the operation was made, their change is kept
+	OfflineOpConflict = "conflict"
+	// OfflineOpRejected means the operation is invalid and was not applied
+	OfflineOpRejected = "rejected"
+)
+
+// MaxOfflineOps is the largest batch of offline operations that is replayed at once
+const MaxOfflineOps = 200
+
+// maxClientItemIDLength matches the size of shoplist_items.client_id
+const maxClientItemIDLength = 64
+
+// offlineReplay holds the state of a replay while its transaction is open
+type offlineReplay struct {
+	tx         *gorm.DB
+	userID     string
+	shoplistID int
+	now        time.Time
+	// touched holds the items changed earlier in the batch, the order of the batch decides between those changes
+	touched map[int]bool
+}
+
+// ReplayOfflineOps applies a batch of operations that a client queued while it was offline. The operations are
+// applied in the order of the batch and in one transaction, and there is a result for every operation.
+//
+// Conflicts are resolved by time: an operation on an item that somebody else changed after the operation was made on
+// the client is not applied, and an item that was deleted in the meantime stays deleted. Timestamps in the future
+// count as now so that a client with a wrong clock does not win every conflict. Items added offline carry a client ID
+// so that replaying the same batch again does not add them twice, and later operations can refer to them by that ID.
+func (b *ShoplistBiz) ReplayOfflineOps(ctx context.Context, userID string, shoplistID int, ops []OfflineOp) ([]OfflineOpResult, *ShoplistError) {
+	if len(ops) == 0 {
+		return nil, NewShoplistError(ShoplistInvalidOfflineOp, "Operations are required.")
+	}
+	if len(ops) > MaxOfflineOps {
+		return nil, NewShoplistError(ShoplistTooManyOfflineOps, "Too many operations.")
+	}
+
+	if accessErr := b.checkItemEditAccess(ctx, userID, shoplistID); accessErr != nil {
+		return nil, accessErr
+	}
+
+	results := make([]OfflineOpResult, 0, len(ops))
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		// Lock the shoplist so that the batch is applied as a whole before or after any other change to the list
+		if err := lockShoplist(tx, shoplistID); err != nil {
+			return err
+		}
+
+		replay := &offlineReplay{
+			tx:         tx,
+			userID:     userID,
+			shoplistID: shoplistID,
+			now:        time.Now(),
+			touched:    make(map[int]bool),
+		}
+		for _, op := range ops {
+			result, err := replay.apply(op)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	}); err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to replay operations.")
+	}
+
+	return results, nil
+}
+
+// apply applies a single operation. The error is only set when the transaction has to be rolled back.
+func (r *offlineReplay) apply(op OfflineOp) (OfflineOpResult, error) {
+	result := OfflineOpResult{OpID: op.OpID, ItemID: op.ItemID, ClientItemID: op.ClientItemID}
+
+	if op.Timestamp.IsZero() {
+		return rejectOfflineOp(result, NewShoplistError(ShoplistInvalidOfflineOp, "Timestamp is required.")), nil
+	}
+	if len(op.ClientItemID) > maxClientItemIDLength {
+		return rejectOfflineOp(result, NewShoplistError(ShoplistInvalidOfflineOp, "Client item ID is too long.")), nil
+	}
+
+	opTime := op.Timestamp
+	if opTime.After(r.now) {
+		opTime = r.now
+	}
+
+	switch op.Type {
+	case OfflineOpAddItem:
+		return r.addItem(op, result)
+	case OfflineOpCheckItem:
+		return r.updateItem(op, opTime, result, map[string]interface{}{"is_bought": op.IsBought})
+	case OfflineOpRenameItem:
+		itemName, nameErr := normalizeItemName(op.ItemName)
+		if nameErr != nil {
+			return rejectOfflineOp(result, nameErr), nil
+		}
+		return r.updateItem(op, opTime, result, map[string]interface{}{"item_name": itemName})
+	case OfflineOpDeleteItem:
+		return r.deleteItem(op, opTime, result)
+	default:
+		return rejectOfflineOp(result, NewShoplistError(ShoplistInvalidOfflineOp, "Unknown operation type.")), nil
+	}
+}
+
+// addItem adds an item that was created offline, unless an earlier replay added it already
+func (r *offlineReplay) addItem(op OfflineOp, result OfflineOpResult) (OfflineOpResult, error) {
+	if op.ClientItemID == "" {
+		return rejectOfflineOp(result, NewShoplistError(ShoplistInvalidOfflineOp, "Client item ID is required.")), nil
+	}
+
+	existing, err := r.findItem(OfflineOp{ClientItemID: op.ClientItemID})
+	if err != nil {
+		return result, err
+	}
+	if existing != nil {
+		result.Status = OfflineOpDuplicate
+		result.ItemID = existing.ID
+		if !existing.DeletedAt.Valid {
+			result.Item = existing
+		}
+		return result, nil
+	}
+
+	item, itemErr := newShoplistItem(r.shoplistID, op.ItemName, op.BrandName, op.ExtraInfo, "", op.Quantity, op.Unit, op.Category)
+	if itemErr != nil {
+		return rejectOfflineOp(result, itemErr), nil
+	}
+	item.ClientID = op.ClientItemID
+
+	if err := insertShoplistItem(r.tx, r.userID, &item); err != nil {
+		return result, err
+	}
+
+	r.touched[item.ID] = true
+	result.Status = OfflineOpApplied
+	result.ItemID = item.ID
+	result.Item = &item
+	return result, nil
+}
+
+// updateItem applies the updates to an item unless somebody else changed or deleted the item after opTime
+func (r *offlineReplay) updateItem(op OfflineOp, opTime time.Time, result OfflineOpResult, updates map[string]interface{}) (OfflineOpResult, error) {
+	item, err := r.findItem(op)
+	if err != nil {
+		return result, err
+	}
+	if item == nil {
+		return rejectOfflineOp(result, NewShoplistError(ShoplistItemNotFound, "Item not found.")), nil
+	}
+	result.ItemID = item.ID
+
+	if item.DeletedAt.Valid {
+		result.Status = OfflineOpConflict
+		return result, nil
+	}
+	if r.changedAfter(*item, opTime) {
+		result.Status = OfflineOpConflict
+		result.Item = item
+		return result, nil
+	}
+
+	// A recurring item checked off offline comes back counting from when it was checked off
+	if isBought, found := updates["is_bought"].(bool); found {
+		updates["recurrence_due_at"] = recurrenceDueAt(*item, item.Recurrence, isBought, opTime)
+	}
+
+	before := itemActivityValues(*item)
+	wasBought := item.IsBought
+	updates["version"] = item.Version + 1
+	if err := r.tx.Model(item).Updates(updates).Error; err != nil {
+		return result, err
+	}
+
+	// The item was bought when the client checked it off, not when the batch was replayed
+	if !wasBought && item.IsBought {
+		if err := recordPurchases(r.tx, r.userID, []db.ShoplistItem{*item}, opTime); err != nil {
+			return result, err
+		}
+	}
+	if err := recordActivity(r.tx, r.shoplistID, r.userID, ActivityItemUpdated, item.ID, before, itemActivityValues(*item)); err != nil {
+		return result, err
+	}
+
+	r.touched[item.ID] = true
+	result.Status = OfflineOpApplied
+	result.Item = item
+	return result, nil
+}
+
+// deleteItem moves an item to the trash unless somebody else changed it after opTime
+func (r *offlineReplay) deleteItem(op OfflineOp, opTime time.Time, result OfflineOpResult) (OfflineOpResult, error) {
+	item, err := r.findItem(op)
+	if err != nil {
+		return result, err
+	}
+	if item == nil {
+		return rejectOfflineOp(result, NewShoplistError(ShoplistItemNotFound, "Item not found.")), nil
+	}
+	result.ItemID = item.ID
+
+	if item.DeletedAt.Valid {
+		result.Status = OfflineOpDuplicate
+		return result, nil
+	}
+	if r.changedAfter(*item, opTime) {
+		result.Status = OfflineOpConflict
+		result.Item = item
+		return result, nil
+	}
+
+	// Soft delete the item so that it can be restored from the trash
+	if err := r.tx.Delete(item).Error; err != nil {
+		return result, err
+	}
+	if err := recordActivity(r.tx, r.shoplistID, r.userID, ActivityItemRemoved, item.ID, itemActivityValues(*item), nil); err != nil {
+		return result, err
+	}
+
+	r.touched[item.ID] = true
+	result.Status = OfflineOpApplied
+	return result, nil
+}
+
+// findItem locks and returns the item of the shoplist an operation refers to, including items in the trash.
+// The item is nil when it does not exist.
+func (r *offlineReplay) findItem(op OfflineOp) (*db.ShoplistItem, error) {
+	query := r.tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("shop_list_id = ?", r.shoplistID)
+	switch {
+	case op.ItemID != 0:
+		query = query.Where("id = ?", op.ItemID)
+	case op.ClientItemID != "":
+		query = query.Where("client_id = ?", op.ClientItemID)
+	default:
+		return nil, nil
+	}
+
+	var item db.ShoplistItem
+	if err := query.First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// changedAfter reports whether somebody else changed the item after the operation was made. Changes made earlier in
+// the same batch do not count, they come from the same client and the batch is in the order the client made them.
+func (r *offlineReplay) changedAfter(item db.ShoplistItem, opTime time.Time) bool {
+	return !r.touched[item.ID] && item.UpdatedAt.After(opTime)
+}
+
+// rejectOfflineOp marks an operation as rejected with the reason
+func rejectOfflineOp(result OfflineOpResult, reason *ShoplistError) OfflineOpResult {
+	result.Status = OfflineOpRejected
+	result.ErrCode = reason.ErrCode
+	result.Message = reason.Message
+	return result
+}
@@ -0,0 +1,55 @@
+package bizshoplist
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+func TestOfflineReplayRejectsInvalidOps(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name            string
+		op              OfflineOp
+		expectedErrCode string
+	}{
+		{name: "missing timestamp", op: OfflineOp{OpID: "op-1", Type: OfflineOpCheckItem, ItemID: 1}, expectedErrCode: ShoplistInvalidOfflineOp},
+		{name: "unknown type", op: OfflineOp{OpID: "op-2", Type: "move_item", Timestamp: now, ItemID: 1}, expectedErrCode: ShoplistInvalidOfflineOp},
+		{name: "client item ID too long", op: OfflineOp{OpID: "op-3", Type: OfflineOpAddItem, Timestamp: now, ClientItemID: strings.Repeat("a", 65)}, expectedErrCode: ShoplistInvalidOfflineOp},
+		{name: "add without client item ID", op: OfflineOp{OpID: "op-4", Type: OfflineOpAddItem, Timestamp: now, ItemName: "Milk"}, expectedErrCode: ShoplistInvalidOfflineOp},
+		{name: "rename to empty name", op: OfflineOp{OpID: "op-5", Type: OfflineOpRenameItem, Timestamp: now, ItemID: 1}, expectedErrCode: ShoplistItemNameEmpty},
+		{name: "rename to blank name", op: OfflineOp{OpID: "op-6", Type: OfflineOpRenameItem, Timestamp: now, ItemID: 1, ItemName: "   "}, expectedErrCode: ShoplistItemNameEmpty},
+	}
+
+	// Invalid operations are rejected before the database is used
+	replay := &offlineReplay{now: now, touched: make(map[int]bool)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := replay.apply(tt.op)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.op.OpID, result.OpID)
+			assert.Equal(t, OfflineOpRejected, result.Status)
+			assert.Equal(t, tt.expectedErrCode, result.ErrCode)
+			assert.Nil(t, result.Item)
+		})
+	}
+}
+
+func TestOfflineReplayChangedAfter(t *testing.T) {
+	changedAt := time.Now()
+	item := db.ShoplistItem{Model: gorm.Model{UpdatedAt: changedAt}, ID: 1}
+	replay := &offlineReplay{now: changedAt, touched: make(map[int]bool)}
+
+	// A change by somebody else after the operation wins
+	assert.True(t, replay.changedAfter(item, changedAt.Add(-time.Minute)))
+	assert.False(t, replay.changedAfter(item, changedAt))
+	assert.False(t, replay.changedAfter(item, changedAt.Add(time.Minute)))
+
+	// Changes earlier in the same batch follow the order of the batch
+	replay.touched[item.ID] = true
+	assert.False(t, replay.changedAfter(item, changedAt.Add(-time.Minute)))
+}
@@ -0,0 +1,151 @@
+package bizshoplist
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// Purchase history page sizes
+const (
+	DefaultPurchaseHistoryPage = 50
+	MaxPurchaseHistoryPage     = 100
+)
+
+// PurchaseHistoryQuery filters the purchase history. Zero times leave the range open.
+type PurchaseHistoryQuery struct {
+	// From is the earliest purchase time, inclusive
+	From time.Time
+	// To is the latest purchase time, exclusive
+	To time.Time
+	// BeforeID starts the page after the purchase with this ID, 0 for the newest purchase
+	BeforeID int
+	// Limit is the number of purchases on a page
+	Limit int
+}
+
+// recordPurchases adds an entry to the purchase history for every item that was marked as bought
+// gormDB Context already established before calling this function
+func recordPurchases(tx *gorm.DB, buyerID string, items []db.ShoplistItem, purchasedAt time.Time) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	purchases := make([]db.ShoplistPurchase, 0, len(items))
+	for _, item := range items {
+		purchases = append(purchases, db.ShoplistPurchase{
+			ShopListID:  item.ShopListID,
+			ItemID:      item.ID,
+			ItemName:    item.ItemName,
+			BrandName:   item.BrandName,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			Category:    item.Category,
+			BuyerID:     buyerID,
+			PurchasedAt: purchasedAt,
+		})
+	}
+
+	return tx.Create(&purchases).Error
+}
+
+// GetShoplistPurchaseHistory returns a page of the purchases of a shoplist, newest first. The user must be a member
+// of the shoplist.
+func (b *ShoplistBiz) GetShoplistPurchaseHistory(ctx context.Context, userID string, shoplistID int, query PurchaseHistoryQuery) ([]ShoplistPurchase, *ShoplistError) {
+	if _, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID); !isMember {
+		return nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	return b.getPurchaseHistory(ctx, query, "shoplist_purchases.shop_list_id = ?", shoplistID)
+}
+
+// GetUserPurchaseHistory returns a page of the purchases the user made in any shoplist, newest first
+func (b *ShoplistBiz) GetUserPurchaseHistory(ctx context.Context, userID string, query PurchaseHistoryQuery) ([]ShoplistPurchase, *ShoplistError) {
+	return b.getPurchaseHistory(ctx, query, "shoplist_purchases.buyer_id = ?", userID)
+}
+
+// GetShoplistPurchaseCounts returns how often every item was bought in a shoplist, most bought first. The user must
+// be a member of the shoplist.
+func (b *ShoplistBiz) GetShoplistPurchaseCounts(ctx context.Context, userID string, shoplistID int, query PurchaseHistoryQuery) ([]PurchaseCount, *ShoplistError) {
+	if _, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID); !isMember {
+		return nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	return b.getPurchaseCounts(ctx, query, "shoplist_purchases.shop_list_id = ?", shoplistID)
+}
+
+// GetUserPurchaseCounts returns how often the user bought every item in any shoplist, most bought first
+func (b *ShoplistBiz) GetUserPurchaseCounts(ctx context.Context, userID string, query PurchaseHistoryQuery) ([]PurchaseCount, *ShoplistError) {
+	return b.getPurchaseCounts(ctx, query, "shoplist_purchases.buyer_id = ?", userID)
+}
+
+// purchaseHistoryScope selects the purchases matching the condition within the date range of the query
+func (b *ShoplistBiz) purchaseHistoryScope(ctx context.Context, query PurchaseHistoryQuery, condition string, arg interface{}) *gorm.DB {
+	scope := b.dbPool.GetDB().WithContext(ctx).Table("shoplist_purchases").
+		Where("shoplist_purchases.deleted_at IS NULL").
+		Where(condition, arg)
+	if !query.From.IsZero() {
+		scope = scope.Where("shoplist_purchases.purchased_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		scope = scope.Where("shoplist_purchases.purchased_at < ?", query.To)
+	}
+	return scope
+}
+
+func (b *ShoplistBiz) getPurchaseHistory(ctx context.Context, query PurchaseHistoryQuery, condition string, arg interface{}) ([]ShoplistPurchase, *ShoplistError) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPurchaseHistoryPage
+	}
+	if limit > MaxPurchaseHistoryPage {
+		limit = MaxPurchaseHistoryPage
+	}
+
+	// The shoplist may have been purged since, its name is then left empty
+	scope := b.purchaseHistoryScope(ctx, query, condition, arg).
+		Select(`shoplist_purchases.id as id, shoplist_purchases.shop_list_id as shop_list_id,
+			COALESCE(shoplists.name, '') as shop_list_name, shoplist_purchases.item_id as item_id,
+			shoplist_purchases.item_name as item_name, shoplist_purchases.brand_name as brand_name,
+			shoplist_purchases.quantity as quantity, shoplist_purchases.unit as unit, shoplist_purchases.category as category,
+			shoplist_purchases.buyer_id as buyer_id, COALESCE(users.nickname, '') as buyer_nickname,
+			shoplist_purchases.purchased_at as purchased_at`).
+		Joins("LEFT JOIN shoplists ON shoplist_purchases.shop_list_id = shoplists.id").
+		Joins("LEFT JOIN users ON shoplist_purchases.buyer_id = users.id")
+	if query.BeforeID > 0 {
+		scope = scope.Where("shoplist_purchases.id < ?", query.BeforeID)
+	}
+
+	purchases := make([]ShoplistPurchase, 0)
+	if err := scope.Order("shoplist_purchases.id DESC").Limit(limit).Scan(&purchases).Error; err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get purchase history.")
+	}
+
+	return purchases, nil
+}
+
+func (b *ShoplistBiz) getPurchaseCounts(ctx context.Context, query PurchaseHistoryQuery, condition string, arg interface{}) ([]PurchaseCount, *ShoplistError) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPurchaseHistoryPage
+	}
+	if limit > MaxPurchaseHistoryPage {
+		limit = MaxPurchaseHistoryPage
+	}
+
+	counts := make([]PurchaseCount, 0)
+	err := b.purchaseHistoryScope(ctx, query, condition, arg).
+		Select(`MIN(shoplist_purchases.item_name) as item_name, MIN(shoplist_purchases.brand_name) as brand_name,
+			COUNT(*) as count, MAX(shoplist_purchases.purchased_at) as last_purchased_at`).
+		Group("LOWER(shoplist_purchases.item_name), LOWER(shoplist_purchases.brand_name)").
+		Order("count DESC, last_purchased_at DESC").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get purchase counts.")
+	}
+
+	return counts, nil
+}
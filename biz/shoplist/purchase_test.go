@@ -0,0 +1,92 @@
+package bizshoplist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	dbmodel "netherealmstudio.com/m/v2/db"
+	testutil "netherealmstudio.com/m/v2/testUtil"
+)
+
+func TestPurchaseHistory(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+	ctx := context.Background()
+
+	isBought := true
+
+	// Marking an item as bought records a purchase
+	_, shoplistErr := biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &isBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	purchases, shoplistErr := biz.GetShoplistPurchaseHistory(ctx, "test_user", 1, PurchaseHistoryQuery{})
+	assert.Nil(t, shoplistErr)
+	if assert.Len(t, purchases, 1) {
+		assert.Equal(t, 1, purchases[0].ItemID)
+		assert.Equal(t, "Item 1", purchases[0].ItemName)
+		assert.Equal(t, "Brand 1", purchases[0].BrandName)
+		assert.Equal(t, "test_user", purchases[0].BuyerID)
+		assert.Equal(t, "Test User", purchases[0].BuyerNickname)
+		assert.Equal(t, 1, purchases[0].ShopListID)
+		assert.WithinDuration(t, time.Now(), purchases[0].PurchasedAt, time.Minute)
+	}
+
+	// An item that stays bought is not bought again
+	_, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &isBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	// Marking all items as bought records the items that were not bought yet
+	itemIDs, shoplistErr := biz.MarkAllItemsBought(ctx, "test_user", 1)
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, []int{3}, itemIDs)
+
+	purchases, shoplistErr = biz.GetShoplistPurchaseHistory(ctx, "test_user", 1, PurchaseHistoryQuery{})
+	assert.Nil(t, shoplistErr)
+	if assert.Len(t, purchases, 2) {
+		assert.Equal(t, 3, purchases[0].ItemID)
+		assert.Equal(t, 1, purchases[1].ItemID)
+	}
+
+	// Pages follow the purchase IDs
+	page, shoplistErr := biz.GetShoplistPurchaseHistory(ctx, "test_user", 1, PurchaseHistoryQuery{Limit: 1, BeforeID: purchases[0].ID})
+	assert.Nil(t, shoplistErr)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, purchases[1].ID, page[0].ID)
+	}
+
+	// The history is kept when the item is removed
+	shoplistErr = biz.RemoveItemFromShopList(ctx, "test_user", 1, 3, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	purchases, shoplistErr = biz.GetUserPurchaseHistory(ctx, "test_user", PurchaseHistoryQuery{})
+	assert.Nil(t, shoplistErr)
+	assert.Len(t, purchases, 2)
+
+	// The date range excludes purchases outside of it
+	purchases, shoplistErr = biz.GetUserPurchaseHistory(ctx, "test_user", PurchaseHistoryQuery{To: time.Now().AddDate(0, 0, -1)})
+	assert.Nil(t, shoplistErr)
+	assert.Empty(t, purchases)
+
+	counts, shoplistErr := biz.GetShoplistPurchaseCounts(ctx, "test_user", 1, PurchaseHistoryQuery{})
+	assert.Nil(t, shoplistErr)
+	assert.Len(t, counts, 2)
+	for _, count := range counts {
+		assert.Equal(t, 1, count.Count)
+	}
+
+	var stored []dbmodel.ShoplistPurchase
+	err := dbPool.GetDB().Find(&stored).Error
+	assert.NoError(t, err)
+	assert.Len(t, stored, 2)
+
+	// Other users only see the purchases of their shoplists
+	_, shoplistErr = biz.GetShoplistPurchaseHistory(ctx, "test_user2", 1, PurchaseHistoryQuery{})
+	assert.Equal(t, ShoplistNotFound, shoplistErr.ErrCode)
+
+	purchases, shoplistErr = biz.GetUserPurchaseHistory(ctx, "test_user2", PurchaseHistoryQuery{})
+	assert.Nil(t, shoplistErr)
+	assert.Empty(t, purchases)
+}
@@ -0,0 +1,183 @@
+package bizshoplist
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"netherealmstudio.com/m/v2/db"
+)
+
+const (
+	// recurrenceEveryDaysPrefix starts a rule that brings an item back a number of days after it was bought, e.g. days:3
+	recurrenceEveryDaysPrefix = "days:"
+	// recurrenceWeeklyPrefix starts a rule that brings an item back on the next given weekday, e.g. weekly:saturday
+	recurrenceWeeklyPrefix = "weekly:"
+
+	// MaxRecurrenceDays is the longest interval of a days rule
+	MaxRecurrenceDays = 365
+
+	// RecurrenceActorID is the actor of the activities recorded when recurring items come back
+	RecurrenceActorID = "system"
+
+	// maxRecurringItemsPerRun bounds the number of items brought back in one run of the scheduler
+	maxRecurringItemsPerRun = 500
+)
+
+// RecurrenceRule is a parsed recurrence rule of an item. The zero value means the item does not recur.
+type RecurrenceRule struct {
+	// EveryDays is the number of days after being bought the item comes back, 0 for a weekly rule
+	EveryDays int
+	// Weekly is set for a weekly rule, the item comes back on Weekday
+	Weekly  bool
+	Weekday time.Weekday
+}
+
+// ParseRecurrenceRule parses a recurrence rule of the form days:N or weekly:<weekday>. An empty rule means the item
+// does not recur.
+func ParseRecurrenceRule(rule string) (RecurrenceRule, error) {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+	if rule == "" {
+		return RecurrenceRule{}, nil
+	}
+
+	if days, found := strings.CutPrefix(rule, recurrenceEveryDaysPrefix); found {
+		everyDays, err := strconv.Atoi(days)
+		if err != nil {
+			return RecurrenceRule{}, err
+		}
+		if everyDays <= 0 || everyDays > MaxRecurrenceDays {
+			return RecurrenceRule{}, errors.New("recurrence interval out of range")
+		}
+		return RecurrenceRule{EveryDays: everyDays}, nil
+	}
+
+	if weekday, found := strings.CutPrefix(rule, recurrenceWeeklyPrefix); found {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.ToLower(day.String()) == weekday {
+				return RecurrenceRule{Weekly: true, Weekday: day}, nil
+			}
+		}
+		return RecurrenceRule{}, errors.New("unknown recurrence weekday")
+	}
+
+	return RecurrenceRule{}, errors.New("unknown recurrence rule")
+}
+
+// IsZero reports whether the rule means the item does not recur
+func (r RecurrenceRule) IsZero() bool {
+	return r.EveryDays == 0 && !r.Weekly
+}
+
+// String returns the canonical form of the rule, empty when the item does not recur
+func (r RecurrenceRule) String() string {
+	if r.Weekly {
+		return recurrenceWeeklyPrefix + strings.ToLower(r.Weekday.String())
+	}
+	if r.EveryDays > 0 {
+		return recurrenceEveryDaysPrefix + strconv.Itoa(r.EveryDays)
+	}
+	return ""
+}
+
+// NextOccurrence returns when an item that was bought at boughtAt comes back. A days rule counts from the time the
+// item was bought, a weekly rule comes back at the start of the next matching weekday in UTC.
+func (r RecurrenceRule) NextOccurrence(boughtAt time.Time) time.Time {
+	if !r.Weekly {
+		return boughtAt.AddDate(0, 0, r.EveryDays)
+	}
+
+	boughtAt = boughtAt.UTC()
+	days := (int(r.Weekday) - int(boughtAt.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	startOfDay := time.Date(boughtAt.Year(), boughtAt.Month(), boughtAt.Day(), 0, 0, 0, 0, time.UTC)
+	return startOfDay.AddDate(0, 0, days)
+}
+
+// normalizeRecurrenceRule validates a recurrence rule and returns its canonical form
+func normalizeRecurrenceRule(rule string) (string, *ShoplistError) {
+	parsed, err := ParseRecurrenceRule(rule)
+	if err != nil {
+		return "", NewShoplistError(ShoplistItemInvalidRecurrence, "Recurrence must be days:N with N from 1 to 365 or weekly:<weekday>.")
+	}
+	return parsed.String(), nil
+}
+
+// recurrenceDueAt returns when a recurring item comes back after an update. Items without a rule or that are not
+// bought are not due. An item that stays bought under the same rule keeps its due time.
+func recurrenceDueAt(item db.ShoplistItem, rule string, isBought bool, now time.Time) *time.Time {
+	if rule == "" || !isBought {
+		return nil
+	}
+	if item.IsBought && item.Recurrence == rule && item.RecurrenceDueAt != nil {
+		return item.RecurrenceDueAt
+	}
+
+	parsed, err := ParseRecurrenceRule(rule)
+	if err != nil {
+		return nil
+	}
+	dueAt := parsed.NextOccurrence(now)
+	return &dueAt
+}
+
+// ResetDueRecurringItems marks the bought recurring items that are due at now as not bought again and returns the
+// number of items that came back. Items in the trash, or in shoplists in the trash, stay bought.
+func (b *ShoplistBiz) ResetDueRecurringItems(ctx context.Context, now time.Time) (int, *ShoplistError) {
+	var items []db.ShoplistItem
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		activeShoplists := tx.Model(&db.Shoplist{}).Select("id")
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_bought = ? AND recurrence_due_at IS NOT NULL AND recurrence_due_at <= ? AND shop_list_id IN (?)", true, now, activeShoplists).
+			Order("id").Limit(maxRecurringItemsPerRun).Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			before := itemActivityValues(item)
+			if err := tx.Model(&item).Updates(map[string]interface{}{
+				"is_bought":         false,
+				"recurrence_due_at": nil,
+				"version":           item.Version + 1,
+			}).Error; err != nil {
+				return err
+			}
+
+			if err := recordActivity(tx, item.ShopListID, RecurrenceActorID, ActivityItemRecurred, item.ID, before, itemActivityValues(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, NewShoplistError(ShoplistFailedToProcess, "Failed to reset recurring items.")
+	}
+
+	return len(items), nil
+}
+
+// StartRecurrenceScheduler periodically brings back the recurring items that are due until the context is cancelled
+func (b *ShoplistBiz) StartRecurrenceScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := b.ResetDueRecurringItems(ctx, time.Now()); err != nil {
+					logger.Errorf("StartRecurrenceScheduler: Failed to reset recurring items. Error: %s", err.Error())
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,203 @@
+package bizshoplist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	dbmodel "netherealmstudio.com/m/v2/db"
+	testutil "netherealmstudio.com/m/v2/testUtil"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	valid := map[string]RecurrenceRule{
+		"":                 {},
+		"days:3":           {EveryDays: 3},
+		" DAYS:365 ":       {EveryDays: 365},
+		"weekly:saturday":  {Weekly: true, Weekday: time.Saturday},
+		"Weekly:Sunday":    {Weekly: true, Weekday: time.Sunday},
+		"weekly:wednesday": {Weekly: true, Weekday: time.Wednesday},
+	}
+	for rule, expected := range valid {
+		parsed, err := ParseRecurrenceRule(rule)
+		assert.NoError(t, err, rule)
+		assert.Equal(t, expected, parsed, rule)
+	}
+
+	for _, rule := range []string{"days:0", "days:366", "days:-1", "days:x", "weekly:sat", "monthly:1", "3"} {
+		_, err := ParseRecurrenceRule(rule)
+		assert.Error(t, err, rule)
+	}
+
+	normalized, shoplistErr := normalizeRecurrenceRule("Weekly:Sunday")
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, "weekly:sunday", normalized)
+	normalized, shoplistErr = normalizeRecurrenceRule("")
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, "", normalized)
+	_, shoplistErr = normalizeRecurrenceRule("days:0")
+	assert.Equal(t, ShoplistItemInvalidRecurrence, shoplistErr.ErrCode)
+}
+
+func TestRecurrenceNextOccurrence(t *testing.T) {
+	// Wednesday afternoon
+	boughtAt := time.Date(2024, time.May, 15, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, boughtAt.AddDate(0, 0, 3), RecurrenceRule{EveryDays: 3}.NextOccurrence(boughtAt))
+	assert.Equal(t, time.Date(2024, time.May, 18, 0, 0, 0, 0, time.UTC), RecurrenceRule{Weekly: true, Weekday: time.Saturday}.NextOccurrence(boughtAt))
+	// The same weekday comes back a week later
+	assert.Equal(t, time.Date(2024, time.May, 22, 0, 0, 0, 0, time.UTC), RecurrenceRule{Weekly: true, Weekday: time.Wednesday}.NextOccurrence(boughtAt))
+}
+
+func TestRecurrenceDueAt(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 15, 30, 0, 0, time.UTC)
+	dueAt := now.AddDate(0, 0, 1)
+
+	// Not due without a rule or when not bought
+	assert.Nil(t, recurrenceDueAt(dbmodel.ShoplistItem{}, "", true, now))
+	assert.Nil(t, recurrenceDueAt(dbmodel.ShoplistItem{IsBought: true, Recurrence: "days:2", RecurrenceDueAt: &dueAt}, "days:2", false, now))
+
+	// Marked as bought
+	assert.Equal(t, now.AddDate(0, 0, 2), *recurrenceDueAt(dbmodel.ShoplistItem{Recurrence: "days:2"}, "days:2", true, now))
+
+	// Stays bought under the same rule
+	assert.Equal(t, dueAt, *recurrenceDueAt(dbmodel.ShoplistItem{IsBought: true, Recurrence: "days:2", RecurrenceDueAt: &dueAt}, "days:2", true, now))
+
+	// Rule changed while bought
+	assert.Equal(t, now.AddDate(0, 0, 5), *recurrenceDueAt(dbmodel.ShoplistItem{IsBought: true, Recurrence: "days:2", RecurrenceDueAt: &dueAt}, "days:5", true, now))
+}
+
+func TestResetDueRecurringItems(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+	ctx := context.Background()
+
+	recurrence := "days:2"
+	isBought := true
+
+	// Item 1 recurs and is marked as bought
+	item, shoplistErr := biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{Recurrence: &recurrence}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, "days:2", item.Recurrence)
+	assert.Nil(t, item.RecurrenceDueAt)
+
+	item, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &isBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+	assert.True(t, item.IsBought)
+	if assert.NotNil(t, item.RecurrenceDueAt) {
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 2), *item.RecurrenceDueAt, time.Minute)
+	}
+
+	invalid := "weekly:someday"
+	_, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{Recurrence: &invalid}, AnyVersion)
+	assert.Equal(t, ShoplistItemInvalidRecurrence, shoplistErr.ErrCode)
+
+	// Nothing is due yet
+	count, shoplistErr := biz.ResetDueRecurringItems(ctx, time.Now())
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, 0, count)
+
+	// Once due the item comes back as not bought
+	count, shoplistErr = biz.ResetDueRecurringItems(ctx, time.Now().AddDate(0, 0, 3))
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, 1, count)
+
+	var stored dbmodel.ShoplistItem
+	err := dbPool.GetDB().First(&stored, 1).Error
+	assert.NoError(t, err)
+	assert.False(t, stored.IsBought)
+	assert.Nil(t, stored.RecurrenceDueAt)
+	assert.Equal(t, "days:2", stored.Recurrence)
+	assert.Equal(t, item.Version+1, stored.Version)
+
+	var activity dbmodel.ShoplistActivity
+	err = dbPool.GetDB().Where("shop_list_id = ? AND item_id = ?", 1, 1).Order("id DESC").First(&activity).Error
+	assert.NoError(t, err)
+	assert.Equal(t, ActivityItemRecurred, activity.Action)
+	assert.Equal(t, RecurrenceActorID, activity.ActorID)
+
+	// Unmarking a bought item stops it from coming back
+	_, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &isBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+	notBought := false
+	item, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &notBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+	assert.Nil(t, item.RecurrenceDueAt)
+}
+
+func TestMarkAllItemsBoughtRecurrence(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+	ctx := context.Background()
+
+	recurrence := "days:2"
+	_, shoplistErr := biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{Recurrence: &recurrence}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	// Marking all items as bought schedules the recurring item only
+	itemIDs, shoplistErr := biz.MarkAllItemsBought(ctx, "test_user", 1)
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, []int{1, 3}, itemIDs)
+
+	var recurring, other dbmodel.ShoplistItem
+	assert.NoError(t, dbPool.GetDB().First(&recurring, 1).Error)
+	assert.NoError(t, dbPool.GetDB().First(&other, 3).Error)
+	if assert.NotNil(t, recurring.RecurrenceDueAt) {
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 2), *recurring.RecurrenceDueAt, time.Minute)
+	}
+	assert.Nil(t, other.RecurrenceDueAt)
+
+	count, shoplistErr := biz.ResetDueRecurringItems(ctx, time.Now().AddDate(0, 0, 3))
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, dbPool.GetDB().First(&recurring, 1).Error)
+	assert.False(t, recurring.IsBought)
+
+	// Unmarking all items stops them from coming back
+	_, shoplistErr = biz.MarkAllItemsBought(ctx, "test_user", 1)
+	assert.Nil(t, shoplistErr)
+	_, shoplistErr = biz.UnmarkAllItemsBought(ctx, "test_user", 1)
+	assert.Nil(t, shoplistErr)
+
+	assert.NoError(t, dbPool.GetDB().First(&recurring, 1).Error)
+	assert.Nil(t, recurring.RecurrenceDueAt)
+
+	count, shoplistErr = biz.ResetDueRecurringItems(ctx, time.Now().AddDate(0, 0, 3))
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, 0, count)
+}
+
+func TestReplayCheckItemRecurrence(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+	ctx := context.Background()
+
+	recurrence := "days:2"
+	_, shoplistErr := biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{Recurrence: &recurrence}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	// The item was checked off offline an hour ago
+	checkedAt := time.Now().Add(-time.Hour)
+	results, shoplistErr := biz.ReplayOfflineOps(ctx, "test_user", 1, []OfflineOp{
+		{OpID: "op-1", Type: OfflineOpCheckItem, Timestamp: checkedAt, ItemID: 1, IsBought: true},
+	})
+	assert.Nil(t, shoplistErr)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, OfflineOpApplied, results[0].Status)
+	}
+
+	var stored dbmodel.ShoplistItem
+	assert.NoError(t, dbPool.GetDB().First(&stored, 1).Error)
+	if assert.NotNil(t, stored.RecurrenceDueAt) {
+		assert.WithinDuration(t, checkedAt.AddDate(0, 0, 2), *stored.RecurrenceDueAt, time.Second)
+	}
+
+	count, shoplistErr := biz.ResetDueRecurringItems(ctx, time.Now().AddDate(0, 0, 3))
+	assert.Nil(t, shoplistErr)
+	assert.Equal(t, 1, count)
+}
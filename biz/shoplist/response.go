@@ -7,6 +7,7 @@ type ShoplistData struct {
 	Members    map[string]struct {
 		MemberID string
 		Nickname string
+		Role     string
 	}
 }
 
@@ -1,19 +1,32 @@
 package bizshoplist
 
 import (
-	"math/rand"
+	"crypto/rand"
 	"time"
 
 	"gorm.io/gorm"
 	"netherealmstudio.com/m/v2/db"
 )
 
-// generateShareCode creates a random alphanumeric code of specified length
+// GenerateShareCode creates a random alphanumeric code of specified length from a cryptographically secure source
 func GenerateShareCode(length int) string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// Reject random bytes at or above the largest multiple of the charset size to keep the characters uniformly distributed
+	const maxByte = 256 - 256%len(charset)
+
 	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+	buf := make([]byte, length)
+	for i := 0; i < length; {
+		rand.Read(buf)
+		for _, r := range buf {
+			if i == length {
+				break
+			}
+			if int(r) < maxByte {
+				b[i] = charset[int(r)%len(charset)]
+				i++
+			}
+		}
 	}
 	return string(b)
 }
@@ -108,3 +108,15 @@ func TestVerifyShareCodeNonExistent(t *testing.T) {
 	result := VerifyShareCodeFromDB(testDB.GetDB(), "NONEXISTENT")
 	assert.True(t, result)
 }
+
+func TestShareCodeGenDistribution(t *testing.T) {
+	// Every character of the charset should show up over enough codes
+	seen := make(map[rune]bool)
+	for i := 0; i < 1000; i++ {
+		for _, r := range GenerateShareCode(6) {
+			seen[r] = true
+		}
+	}
+
+	assert.Equal(t, 36, len(seen))
+}
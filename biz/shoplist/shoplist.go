@@ -2,8 +2,9 @@ package bizshoplist
 
 import (
 	"context"
-	"sort"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	bizmodels "netherealmstudio.com/m/v2/biz"
 	"netherealmstudio.com/m/v2/db"
 )
@@ -17,9 +18,10 @@ func (b *ShoplistBiz) checkShoplistMembershipFromDB(ctx context.Context, userID
 
 // helper function to get shoplist and members relationship and transform the data into struct for easier use
 func (b *ShoplistBiz) GetShoplistWithMembers(ctx context.Context, shoplistID int) (*ShoplistData, *ShoplistError) {
-	rows, err := b.dbPool.GetDB().WithContext(ctx).Raw(`SELECT shop_list_id, owner_id, member_id, nickname as member_nickname FROM users RIGHT JOIN
-		(SELECT shoplists.id as shop_list_id, shoplists.owner_id as owner_id, shoplist_members.member_id as member_id from shoplists 
-		LEFT JOIN shoplist_members ON shoplists.id = shoplist_members.shop_list_id WHERE shoplists.id = ?) as tbl1 ON tbl1.member_id = users.id`, shoplistID).Rows()
+	rows, err := b.dbPool.GetDB().WithContext(ctx).Raw(`SELECT shop_list_id, owner_id, member_id, nickname as member_nickname, role FROM users RIGHT JOIN
+		(SELECT shoplists.id as shop_list_id, shoplists.owner_id as owner_id, shoplist_members.member_id as member_id, shoplist_members.role as role from shoplists 
+		LEFT JOIN shoplist_members ON shoplists.id = shoplist_members.shop_list_id AND shoplist_members.deleted_at IS NULL
+		WHERE shoplists.id = ? AND shoplists.deleted_at IS NULL) as tbl1 ON tbl1.member_id = users.id`, shoplistID).Rows()
 
 	if err != nil {
 		return nil, NewShoplistError(ShoplistNotFound, err.Error())
@@ -31,6 +33,7 @@ func (b *ShoplistBiz) GetShoplistWithMembers(ctx context.Context, shoplistID int
 		OwnerID    string `json:"owner_id" gorm:"column:owner_id"`
 		MemberID   string `json:"member_id" gorm:"column:member_id"`
 		Nickname   string `json:"nickname" gorm:"column:nickname"`
+		Role       string `json:"role" gorm:"column:role"`
 	}
 
 	hasRows := false
@@ -40,7 +43,7 @@ func (b *ShoplistBiz) GetShoplistWithMembers(ctx context.Context, shoplistID int
 	for rows.Next() {
 		hasRows = true
 		var queryShoplist QueryResult
-		err := rows.Scan(&queryShoplist.ShopListID, &queryShoplist.OwnerID, &queryShoplist.MemberID, &queryShoplist.Nickname)
+		err := rows.Scan(&queryShoplist.ShopListID, &queryShoplist.OwnerID, &queryShoplist.MemberID, &queryShoplist.Nickname, &queryShoplist.Role)
 		if err != nil {
 			return nil, NewShoplistError(ShoplistNotFound, err.Error())
 		}
@@ -51,6 +54,7 @@ func (b *ShoplistBiz) GetShoplistWithMembers(ctx context.Context, shoplistID int
 			shopListData.Members = make(map[string]struct {
 				MemberID string
 				Nickname string
+				Role     string
 			})
 		}
 
@@ -58,7 +62,8 @@ func (b *ShoplistBiz) GetShoplistWithMembers(ctx context.Context, shoplistID int
 			shopListData.Members[queryShoplist.MemberID] = struct {
 				MemberID string
 				Nickname string
-			}{MemberID: queryShoplist.MemberID, Nickname: queryShoplist.Nickname}
+				Role     string
+			}{MemberID: queryShoplist.MemberID, Nickname: queryShoplist.Nickname, Role: effectiveMemberRole(queryShoplist.OwnerID, queryShoplist.MemberID, queryShoplist.Role)}
 		}
 	}
 
@@ -78,7 +83,8 @@ func (b *ShoplistBiz) CreateShoplist(ctx context.Context, ownerID string, name s
 	}
 
 	// Start a new transaction
-	tx := b.dbPool.GetDB().WithContext(ctx).Begin()
+	eventCtx, pendingEvents := collectShoplistEvents(ctx)
+	tx := b.dbPool.GetDB().WithContext(eventCtx).Begin()
 
 	// Save to database
 	if err := tx.Create(&shoplist).Error; err != nil {
@@ -89,6 +95,7 @@ func (b *ShoplistBiz) CreateShoplist(ctx context.Context, ownerID string, name s
 	member := db.ShoplistMember{
 		ShopListID: shoplist.ID,
 		MemberID:   ownerID,
+		Role:       MemberRoleOwner,
 	}
 
 	// Add the owner as a member of the shoplist
@@ -97,149 +104,161 @@ func (b *ShoplistBiz) CreateShoplist(ctx context.Context, ownerID string, name s
 		return NewShoplistError(ShoplistFailedToCreate, err.Error())
 	}
 
+	if err := recordActivity(tx, shoplist.ID, ownerID, ActivityShoplistCreated, 0, nil, activityValues{"name": name}); err != nil {
+		tx.Rollback() // Rollback the transaction on error
+		return NewShoplistError(ShoplistFailedToCreate, err.Error())
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return NewShoplistError(ShoplistFailedToCreate, err.Error())
 	}
 
+	b.publishShoplistEvents(pendingEvents)
 	return nil
 }
 
-// UpdateShoplist updates a shoplist's name
-func (b *ShoplistBiz) UpdateShoplist(ctx context.Context, userID string, shoplistID int, name string) *ShoplistError {
+// UpdateShoplist updates a shoplist's name and returns the new version of the shoplist. With an expected version other
+// than AnyVersion the name is only updated if nobody changed the shoplist since that version.
+func (b *ShoplistBiz) UpdateShoplist(ctx context.Context, userID string, shoplistID int, name string, expectedVersion int) (int, *ShoplistError) {
 
 	shoplistMembership, err := b.GetShoplistWithMembers(ctx, shoplistID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Check if user is a member
 	if _, exists := shoplistMembership.Members[userID]; !exists {
-		return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+		return 0, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
 	}
 
 	// Check if user is the owner
 	if shoplistMembership.OwnerID != userID {
-		return NewShoplistError(ShoplistNotOwned, "User is not the owner of the shoplist")
+		return 0, NewShoplistError(ShoplistNotOwned, "User is not the owner of the shoplist")
 	}
 
 	// Update shoplist name
-	if err := b.dbPool.GetDB().WithContext(ctx).Model(&db.Shoplist{}).Where("id = ?", shoplistID).Update("name", name).Error; err != nil {
-		return NewShoplistError(ShoplistFailedToUpdate, err.Error())
-	}
+	var shoplist db.Shoplist
+	var versionErr *ShoplistError
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", shoplistID).First(&shoplist).Error; err != nil {
+			return err
+		}
 
-	return nil
-}
+		if versionErr = checkVersion(expectedVersion, shoplist.Version); versionErr != nil {
+			return versionErr
+		}
 
-func (b *ShoplistBiz) GetAllShoplistAndItemsForUser(ctx context.Context, userID string) ([]*bizmodels.Shoplist, *ShoplistError) {
-	type QueryResult struct {
-		ShopListID    int     `gorm:"column:shop_list_id"`
-		ShopListName  string  `gorm:"column:shop_list_name"`
-		MemberID      string  `gorm:"column:member_id"`
-		ItemID        *int    `gorm:"column:item_id"`
-		ItemName      *string `gorm:"column:item_name"`
-		BrandName     *string `gorm:"column:brand_name"`
-		ExtraInfo     *string `gorm:"column:extra_info"`
-		IsBought      *bool   `gorm:"column:is_bought"`
-		OwnerID       string  `gorm:"column:owner_id"`
-		OwnerNickname string  `gorm:"column:owner_nickname"`
+		oldName := shoplist.Name
+		if err := tx.Model(&shoplist).Updates(map[string]interface{}{"name": name, "version": shoplist.Version + 1}).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityShoplistRenamed, 0, activityValues{"name": oldName}, activityValues{"name": name})
+	}); err != nil {
+		if versionErr != nil {
+			return 0, versionErr
+		}
+		return 0, NewShoplistError(ShoplistFailedToUpdate, err.Error())
 	}
 
-	var results []QueryResult
-	err := b.dbPool.GetDB().WithContext(ctx).Raw(`
-		SELECT tbl2.shop_list_id as shop_list_id, shop_list_name, member_id, shoplist_items.id as item_id, item_name, brand_name, extra_info, is_bought, owner_id, owner_nickname 
-		FROM (
-			SELECT shop_list_id, owner_id, nickname as owner_nickname, shop_list_name, member_id 
-			FROM (
-				SELECT shoplists.id as shop_list_id, owner_id, name as shop_list_name, member_id 
-				FROM shoplist_members 
-				LEFT JOIN shoplists ON shoplist_members.shop_list_id = shoplists.id 
-				WHERE member_id = ?
-			) as tbl1 
-			LEFT JOIN users on owner_id = users.id
-		) as tbl2
-		LEFT JOIN shoplist_items on tbl2.shop_list_id = shoplist_items.shop_list_id`, userID).Scan(&results).Error
+	return shoplist.Version, nil
+}
 
+// DeleteShoplist removes a shoplist for all of its members. Only the owner can delete a shoplist.
+// The shoplist, its members and items are moved to the owner's trash and its share code is removed.
+// With an expected version other than AnyVersion the shoplist is only deleted if nobody changed it since that version.
+func (b *ShoplistBiz) DeleteShoplist(ctx context.Context, userID string, shoplistID int, expectedVersion int) *ShoplistError {
+	shoplistMembership, err := b.GetShoplistWithMembers(ctx, shoplistID)
 	if err != nil {
-		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplist items.")
+		return err
 	}
 
-	if len(results) == 0 {
-		return nil, NewShoplistError(ShoplistNotFound, "No shoplists found.")
+	// Check if user is a member
+	if _, exists := shoplistMembership.Members[userID]; !exists {
+		return NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
 	}
 
-	// Create a map to store unique shoplists
-	shoplistMap := make(map[int]*bizmodels.Shoplist)
+	// Check if user is the owner
+	if shoplistMembership.OwnerID != userID {
+		return NewShoplistError(ShoplistNotOwner, "Only the owner can delete the shoplist.")
+	}
 
-	// Process results
-	for _, r := range results {
-		// Add shoplist if not exists
-		if _, exists := shoplistMap[r.ShopListID]; !exists {
-			shoplistMap[r.ShopListID] = &bizmodels.Shoplist{
-				ID:            r.ShopListID,
-				Name:          r.ShopListName,
-				OwnerID:       r.OwnerID,
-				OwnerNickname: r.OwnerNickname,
-				Items:         make([]bizmodels.ShoplistItem, 0),
-			}
+	var versionErr *ShoplistError
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var shoplist db.Shoplist
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", shoplistID).First(&shoplist).Error; err != nil {
+			return err
 		}
 
-		// Add item if exists
-		if r.ItemID != nil {
-			item := bizmodels.ShoplistItem{
-				ID:         *r.ItemID,
-				ShopListID: r.ShopListID,
-				ItemName:   *r.ItemName,
-				BrandName:  *r.BrandName,
-				ExtraInfo:  *r.ExtraInfo,
-				IsBought:   *r.IsBought,
-			}
-			shoplistMap[r.ShopListID].Items = append(shoplistMap[r.ShopListID].Items, item)
+		if versionErr = checkVersion(expectedVersion, shoplist.Version); versionErr != nil {
+			return versionErr
+		}
+
+		if err := softDeleteShoplist(tx, shoplistID); err != nil {
+			return err
 		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityShoplistDeleted, 0, nil, nil)
+	}); err != nil {
+		if versionErr != nil {
+			return versionErr
+		}
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to delete shoplist.")
 	}
 
-	// Convert map to slice
-	shoplists := make([]*bizmodels.Shoplist, 0, len(shoplistMap))
-	for _, shoplist := range shoplistMap {
-		shoplists = append(shoplists, shoplist)
+	return nil
+}
+
+// GetAllShoplistAndItemsForUser returns every shoplist the user is a member of with all their items, ordered by ID
+func (b *ShoplistBiz) GetAllShoplistAndItemsForUser(ctx context.Context, userID string) ([]*bizmodels.Shoplist, *ShoplistError) {
+	page, err := b.GetShoplistPageForUser(ctx, userID, ShoplistQuery{})
+	if err != nil {
+		return nil, err
 	}
 
-	// Sort shoplists by ID
-	sort.Slice(shoplists, func(i, j int) bool {
-		return shoplists[i].ID < shoplists[j].ID
-	})
+	if len(page.Shoplists) == 0 {
+		return nil, NewShoplistError(ShoplistNotFound, "No shoplists found.")
+	}
 
-	return shoplists, nil
+	return page.Shoplists, nil
 }
 
 func (b *ShoplistBiz) GetShoplistAndItems(ctx context.Context, userID string, shoplistID int) (*bizmodels.Shoplist, *ShoplistError) {
 	type QueryResult struct {
-		ShopListID    int     `gorm:"column:shop_list_id"`
-		ShopListName  string  `gorm:"column:shop_list_name"`
-		MemberID      string  `gorm:"column:member_id"`
-		ItemID        *int    `gorm:"column:item_id"`
-		ItemName      *string `gorm:"column:item_name"`
-		BrandName     *string `gorm:"column:brand_name"`
-		ExtraInfo     *string `gorm:"column:extra_info"`
-		IsBought      *bool   `gorm:"column:is_bought"`
-		OwnerID       string  `gorm:"column:owner_id"`
-		OwnerNickname string  `gorm:"column:owner_nickname"`
+		ShopListID    int      `gorm:"column:shop_list_id"`
+		ShopListName  string   `gorm:"column:shop_list_name"`
+		MemberID      string   `gorm:"column:member_id"`
+		ItemID        *int     `gorm:"column:item_id"`
+		ItemName      *string  `gorm:"column:item_name"`
+		BrandName     *string  `gorm:"column:brand_name"`
+		ExtraInfo     *string  `gorm:"column:extra_info"`
+		Quantity      *float64 `gorm:"column:quantity"`
+		Unit          *string  `gorm:"column:unit"`
+		Category      *string  `gorm:"column:category"`
+		IsBought      *bool    `gorm:"column:is_bought"`
+		ItemVersion   *int     `gorm:"column:item_version"`
+		Recurrence    *string  `gorm:"column:recurrence"`
+		OwnerID       string   `gorm:"column:owner_id"`
+		OwnerNickname string   `gorm:"column:owner_nickname"`
+		Version       int      `gorm:"column:shop_list_version"`
 	}
 
 	var results []QueryResult
 	err := b.dbPool.GetDB().WithContext(ctx).Raw(`
-		SELECT tbl2.shop_list_id as shop_list_id, shop_list_name, member_id, shoplist_items.id as item_id, item_name, brand_name, extra_info, is_bought, owner_id, owner_nickname 
+		SELECT tbl2.shop_list_id as shop_list_id, shop_list_name, shop_list_version, member_id, shoplist_items.id as item_id, item_name, brand_name, extra_info, quantity, unit, category, is_bought, shoplist_items.version as item_version, recurrence, owner_id, owner_nickname 
 		FROM (
-			SELECT shop_list_id, owner_id, nickname as owner_nickname, shop_list_name, member_id 
+			SELECT shop_list_id, owner_id, nickname as owner_nickname, shop_list_name, shop_list_version, member_id 
 			FROM (
-				SELECT shoplists.id as shop_list_id, owner_id, name as shop_list_name, member_id 
+				SELECT shoplists.id as shop_list_id, owner_id, name as shop_list_name, shoplists.version as shop_list_version, member_id 
 				FROM shoplist_members 
 				LEFT JOIN shoplists ON shoplist_members.shop_list_id = shoplists.id 
-				WHERE member_id = ? and shoplists.id = ?
+				WHERE member_id = ? and shoplists.id = ? AND shoplist_members.deleted_at IS NULL AND shoplists.deleted_at IS NULL
 			) as tbl1 
 			LEFT JOIN users on owner_id = users.id
 		) as tbl2
-		LEFT JOIN shoplist_items on tbl2.shop_list_id = shoplist_items.shop_list_id`, userID, shoplistID).Scan(&results).Error
+		LEFT JOIN shoplist_items on tbl2.shop_list_id = shoplist_items.shop_list_id AND shoplist_items.deleted_at IS NULL
+		ORDER BY shoplist_items.position, shoplist_items.id`, userID, shoplistID).Scan(&results).Error
 
 	if err != nil {
 		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplist items.")
@@ -255,6 +274,7 @@ func (b *ShoplistBiz) GetShoplistAndItems(ctx context.Context, userID string, sh
 		Name:          results[0].ShopListName,
 		OwnerID:       results[0].OwnerID,
 		OwnerNickname: results[0].OwnerNickname,
+		Version:       results[0].Version,
 		Items:         make([]bizmodels.ShoplistItem, 0),
 	}
 
@@ -267,7 +287,12 @@ func (b *ShoplistBiz) GetShoplistAndItems(ctx context.Context, userID string, sh
 				ItemName:   *r.ItemName,
 				BrandName:  *r.BrandName,
 				ExtraInfo:  *r.ExtraInfo,
+				Quantity:   *r.Quantity,
+				Unit:       *r.Unit,
+				Category:   *r.Category,
 				IsBought:   *r.IsBought,
+				Version:    *r.ItemVersion,
+				Recurrence: *r.Recurrence,
 			})
 		}
 	}
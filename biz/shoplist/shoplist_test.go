@@ -163,7 +163,7 @@ func setupSearchTestData(t *testing.T, dbPool *db.MySQLConnectionPool) {
 func TestGetShoplistItemsByUserId(t *testing.T) {
 	dbPool := testutil.SetupTestEnv(t)
 	setupSearchTestData(t, dbPool)
-	biz := InitializeShoplistBiz(*dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
 
 	tests := []struct {
 		name              string
@@ -269,7 +269,7 @@ func TestGetShoplistItemsByUserId(t *testing.T) {
 func TestGetShoplistWithMembers(t *testing.T) {
 	dbPool := testutil.SetupTestEnv(t)
 	setupSearchTestData(t, dbPool)
-	biz := InitializeShoplistBiz(*dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
 
 	tests := []struct {
 		name          string
@@ -286,10 +286,12 @@ func TestGetShoplistWithMembers(t *testing.T) {
 				Members: map[string]struct {
 					MemberID string
 					Nickname string
+					Role     string
 				}{
 					"test_user": {
 						MemberID: "test_user",
 						Nickname: "Test User",
+						Role:     "owner",
 					},
 				},
 			},
@@ -304,14 +306,17 @@ func TestGetShoplistWithMembers(t *testing.T) {
 				Members: map[string]struct {
 					MemberID string
 					Nickname string
+					Role     string
 				}{
 					"test_user2": {
 						MemberID: "test_user2",
 						Nickname: "Test User 2",
+						Role:     "owner",
 					},
 					"test_user": {
 						MemberID: "test_user",
 						Nickname: "Test User",
+						Role:     "editor",
 					},
 				},
 			},
@@ -349,7 +354,7 @@ func TestGetShoplistWithMembers(t *testing.T) {
 func TestGetShoplistAndItems(t *testing.T) {
 	dbPool := testutil.SetupTestEnv(t)
 	setupSearchTestData(t, dbPool)
-	biz := InitializeShoplistBiz(*dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
 
 	tests := []struct {
 		name             string
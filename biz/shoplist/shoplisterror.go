@@ -1,15 +1,42 @@
 package bizshoplist
 
 const (
-	ShoplistNotFound        = "shoplist_not_found"
-	ShoplistNotOwned        = "shoplist_not_owned"
-	ShoplistNotMember       = "shoplist_not_member"
-	ShoplistNotOwner        = "shoplist_not_owner"
-	ShoplistFailedToCreate  = "shoplist_failed_to_create"
-	ShoplistFailedToProcess = "shoplist_failed_to_process"
-	ShoplistFailedToUpdate  = "shoplist_failed_to_update"
-	ShoplistItemNameEmpty   = "shoplist_item_name_empty"
-	ShoplistItemNotFound    = "shoplist_item_not_found"
+	ShoplistNotFound                = "shoplist_not_found"
+	ShoplistNotOwned                = "shoplist_not_owned"
+	ShoplistNotMember               = "shoplist_not_member"
+	ShoplistNotOwner                = "shoplist_not_owner"
+	ShoplistFailedToCreate          = "shoplist_failed_to_create"
+	ShoplistFailedToProcess         = "shoplist_failed_to_process"
+	ShoplistFailedToUpdate          = "shoplist_failed_to_update"
+	ShoplistItemNameEmpty           = "shoplist_item_name_empty"
+	ShoplistItemNotFound            = "shoplist_item_not_found"
+	ShoplistItemInvalidUnit         = "shoplist_item_invalid_unit"
+	ShoplistItemInvalidQuantity     = "shoplist_item_invalid_quantity"
+	ShoplistItemInvalidCategory     = "shoplist_item_invalid_category"
+	ShoplistItemInvalidOrder        = "shoplist_item_invalid_order"
+	ShoplistNewOwnerNotMember       = "shoplist_new_owner_not_member"
+	ShoplistMemberNotFound          = "shoplist_member_not_found"
+	ShoplistCannotRemoveSelf        = "shoplist_cannot_remove_self"
+	ShoplistMemberBanned            = "shoplist_member_banned"
+	ShoplistMemberReadOnly          = "shoplist_member_read_only"
+	ShoplistInvalidMemberRole       = "shoplist_invalid_member_role"
+	ShoplistCannotChangeOwnerRole   = "shoplist_cannot_change_owner_role"
+	ShoplistInvalidShareCodeExpiry  = "shoplist_invalid_share_code_expiry"
+	ShoplistInvalidShareCodeMaxUses = "shoplist_invalid_share_code_max_uses"
+	ShoplistShareCodeExhausted      = "shoplist_share_code_exhausted"
+	ShoplistInviteeNotFound         = "shoplist_invitee_not_found"
+	ShoplistAlreadyMember           = "shoplist_already_member"
+	ShoplistInvitationNotFound      = "shoplist_invitation_not_found"
+	ShoplistInvalidShareCode        = "shoplist_invalid_share_code"
+	ShoplistTooManyJoinAttempts     = "shoplist_too_many_join_attempts"
+	ShoplistVersionConflict         = "shoplist_version_conflict"
+	ShoplistInvalidOfflineOp        = "shoplist_invalid_offline_op"
+	ShoplistTooManyOfflineOps       = "shoplist_too_many_offline_ops"
+	ShoplistInvalidBulkItems        = "shoplist_invalid_bulk_items"
+	ShoplistTooManyBulkItems        = "shoplist_too_many_bulk_items"
+	ShoplistItemNoChanges           = "shoplist_item_no_changes"
+	ShoplistInvalidQuery            = "shoplist_invalid_query"
+	ShoplistItemInvalidRecurrence   = "shoplist_item_invalid_recurrence"
 )
 
 type ShoplistError struct {
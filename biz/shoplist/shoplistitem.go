@@ -2,39 +2,102 @@ package bizshoplist
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"netherealmstudio.com/m/v2/db"
 )
 
-func (b *ShoplistBiz) AddItemToShopList(ctx context.Context, userID string, shoplistID int, itemName string, brandName string, extraInfo string, thumbnail string) (*db.ShoplistItem, *ShoplistError) {
-	if !b.checkShoplistMembershipFromDB(ctx, userID, shoplistID) {
+func (b *ShoplistBiz) AddItemToShopList(ctx context.Context, userID string, shoplistID int, itemName string, brandName string, extraInfo string, thumbnail string, quantity float64, unit string, category string) (*db.ShoplistItem, *ShoplistError) {
+	role, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID)
+	if !isMember {
 		return nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
 	}
 
-	// Create new item
-	newItem := db.ShoplistItem{
+	// check if user can edit items
+	if !canEditItems(role) {
+		return nil, NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	newItem, itemErr := newShoplistItem(shoplistID, itemName, brandName, extraInfo, thumbnail, quantity, unit, category)
+	if itemErr != nil {
+		return nil, itemErr
+	}
+
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		return insertShoplistItem(tx, userID, &newItem)
+	}); err != nil {
+		return nil, NewShoplistError(ShoplistFailedToCreate, "Failed to add item.")
+	}
+
+	return &newItem, nil
+}
+
+// newShoplistItem validates the fields of a new item and returns the item to insert
+func newShoplistItem(shoplistID int, itemName string, brandName string, extraInfo string, thumbnail string, quantity float64, unit string, category string) (db.ShoplistItem, *ShoplistError) {
+	// check if quantity and unit are valid
+	unit, unitErr := validateItemQuantity(quantity, unit)
+	if unitErr != nil {
+		return db.ShoplistItem{}, unitErr
+	}
+
+	itemName, nameErr := normalizeItemName(itemName)
+	if nameErr != nil {
+		return db.ShoplistItem{}, nameErr
+	}
+
+	// classify the item from its name when no category is given
+	if category == "" {
+		category = ClassifyItemCategory(itemName)
+	} else if !IsValidItemCategory(category) {
+		return db.ShoplistItem{}, NewShoplistError(ShoplistItemInvalidCategory, "Category is not a known category.")
+	}
+
+	return db.ShoplistItem{
 		ShopListID: shoplistID,
 		ItemName:   itemName,
 		BrandName:  brandName,
 		ExtraInfo:  extraInfo,
+		Quantity:   quantity,
+		Unit:       unit,
+		Category:   category,
 		IsBought:   false,
 		Thumbnail:  thumbnail,
-	}
+		Version:    1,
+	}, nil
+}
 
-	// check if item name is empty
+// normalizeItemName trims an item name and checks that it is not empty, a name of only spaces is empty as well
+func normalizeItemName(itemName string) (string, *ShoplistError) {
+	itemName = strings.TrimSpace(itemName)
 	if itemName == "" {
-		return nil, NewShoplistError(ShoplistItemNameEmpty, "Item name is required.")
+		return "", NewShoplistError(ShoplistItemNameEmpty, "Item name is required.")
 	}
+	return itemName, nil
+}
 
-	if err := b.dbPool.GetDB().WithContext(ctx).Create(&newItem).Error; err != nil {
-		return nil, NewShoplistError(ShoplistFailedToCreate, "Failed to add item.")
+// insertShoplistItem appends an item to the end of its list and records the activity. The shoplist row is locked so
+// that concurrent inserts and reorders of the same list are serialized.
+// gormDB Context already established before calling this function
+func insertShoplistItem(tx *gorm.DB, userID string, item *db.ShoplistItem) error {
+	position, err := nextItemPosition(tx, item.ShopListID)
+	if err != nil {
+		return err
 	}
+	item.Position = position
 
-	return &newItem, nil
+	if err := tx.Create(item).Error; err != nil {
+		return err
+	}
+
+	return recordActivity(tx, item.ShopListID, userID, ActivityItemAdded, item.ID, nil, itemActivityValues(*item))
 }
 
-func (b *ShoplistBiz) RemoveItemFromShopList(ctx context.Context, userID string, shoplistID int, itemID int) *ShoplistError {
+// RemoveItemFromShopList moves an item to the trash. With an expected version other than AnyVersion the item is only
+// removed if nobody changed it since that version.
+func (b *ShoplistBiz) RemoveItemFromShopList(ctx context.Context, userID string, shoplistID int, itemID int, expectedVersion int) *ShoplistError {
 	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
 	if shopListErr != nil {
 		return shopListErr
@@ -45,26 +108,65 @@ func (b *ShoplistBiz) RemoveItemFromShopList(ctx context.Context, userID string,
 		return NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
 	}
 
+	// check if user can edit items
+	if !canEditItems(shopListData.Members[userID].Role) {
+		return NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		return trashShoplistItem(tx, userID, shoplistID, itemID, expectedVersion)
+	}); err != nil {
+		if shoplistErr, ok := err.(*ShoplistError); ok {
+			return shoplistErr
+		}
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to remove item.")
+	}
+
+	return nil
+}
+
+// trashShoplistItem moves an item to the trash and records the activity. A *ShoplistError is returned when the item
+// does not exist or its version does not match.
+// gormDB Context already established before calling this function
+func trashShoplistItem(tx *gorm.DB, userID string, shoplistID int, itemID int, expectedVersion int) error {
 	// check if item exists and belongs to the shoplist
 	var item db.ShoplistItem
-	err := b.dbPool.GetDB().WithContext(ctx).Where("id = ? AND shop_list_id = ?", itemID, shoplistID).First(&item).Error
-	if err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND shop_list_id = ?", itemID, shoplistID).First(&item).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return NewShoplistError(ShoplistItemNotFound, "Item not found.")
 		}
-		return NewShoplistError(ShoplistFailedToProcess, "Failed to check item.")
+		return err
 	}
 
-	// Delete the item
-	err = b.dbPool.GetDB().WithContext(ctx).Unscoped().Delete(&item).Error
-	if err != nil {
-		return NewShoplistError(ShoplistFailedToProcess, "Failed to remove item.")
+	if versionErr := checkVersion(expectedVersion, item.Version); versionErr != nil {
+		return versionErr
 	}
 
-	return nil
+	// Soft delete the item so that it can be restored from the trash
+	if err := tx.Delete(&item).Error; err != nil {
+		return err
+	}
+
+	return recordActivity(tx, shoplistID, userID, ActivityItemRemoved, item.ID, itemActivityValues(item), nil)
 }
 
-func (b *ShoplistBiz) UpdateShoplistItem(ctx context.Context, userID string, shoplistID int, itemID int, itemName *string, brandName *string, extraInfo *string, isBought *bool) (*db.ShoplistItem, *ShoplistError) {
+// UpdateShoplistItem updates the provided fields of an item. With an expected version other than AnyVersion the update
+// only applies if nobody changed the item since that version.
+func (b *ShoplistBiz) UpdateShoplistItem(ctx context.Context, userID string, shoplistID int, itemID int, itemName *string, brandName *string, extraInfo *string, quantity *float64, unit *string, category *string, isBought *bool, expectedVersion int) (*db.ShoplistItem, *ShoplistError) {
+	return b.UpdateShoplistItemFields(ctx, userID, shoplistID, itemID, ShoplistItemUpdate{
+		ItemName:  itemName,
+		BrandName: brandName,
+		ExtraInfo: extraInfo,
+		Quantity:  quantity,
+		Unit:      unit,
+		Category:  category,
+		IsBought:  isBought,
+	}, expectedVersion)
+}
+
+// UpdateShoplistItemFields updates the fields of an item that are set. With an expected version other than AnyVersion
+// the update only applies if nobody changed the item since that version.
+func (b *ShoplistBiz) UpdateShoplistItemFields(ctx context.Context, userID string, shoplistID int, itemID int, fields ShoplistItemUpdate, expectedVersion int) (*db.ShoplistItem, *ShoplistError) {
 	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
 	if shopListErr != nil {
 		return nil, shopListErr
@@ -75,35 +177,137 @@ func (b *ShoplistBiz) UpdateShoplistItem(ctx context.Context, userID string, sho
 		return nil, NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
 	}
 
-	//check if item exists and belongs to the shoplist
+	// check if user can edit items
+	if !canEditItems(shopListData.Members[userID].Role) {
+		return nil, NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	var item *db.ShoplistItem
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		item, err = updateShoplistItem(tx, userID, shoplistID, itemID, fields, expectedVersion)
+		return err
+	}); err != nil {
+		if shoplistErr, ok := err.(*ShoplistError); ok {
+			return nil, shoplistErr
+		}
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to update item.")
+	}
+
+	return item, nil
+}
+
+// updateShoplistItem applies the provided fields to an item, bumps its version and records the activity.
+// A *ShoplistError is returned when the item does not exist, its version does not match or a field is invalid.
+// gormDB Context already established before calling this function
+func updateShoplistItem(tx *gorm.DB, userID string, shoplistID int, itemID int, fields ShoplistItemUpdate, expectedVersion int) (*db.ShoplistItem, error) {
+	if fields.isEmpty() {
+		return nil, NewShoplistError(ShoplistItemNoChanges, "At least one field must be updated.")
+	}
+	var itemName string
+	if fields.ItemName != nil {
+		var nameErr *ShoplistError
+		if itemName, nameErr = normalizeItemName(*fields.ItemName); nameErr != nil {
+			return nil, nameErr
+		}
+	}
+	if fields.Category != nil && !IsValidItemCategory(*fields.Category) {
+		return nil, NewShoplistError(ShoplistItemInvalidCategory, "Category is not a known category.")
+	}
+	var recurrence string
+	if fields.Recurrence != nil {
+		var recurrenceErr *ShoplistError
+		if recurrence, recurrenceErr = normalizeRecurrenceRule(*fields.Recurrence); recurrenceErr != nil {
+			return nil, recurrenceErr
+		}
+	}
+
+	// check if item exists and belongs to the shoplist, the lock keeps the version check and the update together
 	var item db.ShoplistItem
-	err := b.dbPool.GetDB().WithContext(ctx).Where("id = ? AND shop_list_id = ?", itemID, shoplistID).First(&item).Error
-	if err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND shop_list_id = ?", itemID, shoplistID).First(&item).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, NewShoplistError(ShoplistItemNotFound, "Item not found.")
 		}
-		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to check item.")
+		return nil, err
 	}
 
-	// Update only the fields that are provided in the request
+	if versionErr := checkVersion(expectedVersion, item.Version); versionErr != nil {
+		return nil, versionErr
+	}
+
+	// Update only the fields that are provided
 	updates := make(map[string]interface{})
-	if itemName != nil {
-		updates["item_name"] = *itemName
+	if fields.ItemName != nil {
+		updates["item_name"] = itemName
 	}
-	if brandName != nil {
-		updates["brand_name"] = *brandName
+	if fields.BrandName != nil {
+		updates["brand_name"] = *fields.BrandName
 	}
-	if extraInfo != nil {
-		updates["extra_info"] = *extraInfo
+	if fields.ExtraInfo != nil {
+		updates["extra_info"] = *fields.ExtraInfo
 	}
-	if isBought != nil {
-		updates["is_bought"] = *isBought
+	if fields.Quantity != nil || fields.Unit != nil {
+		// validate the resulting quantity and unit together
+		newQuantity := item.Quantity
+		if fields.Quantity != nil {
+			newQuantity = *fields.Quantity
+		}
+		newUnit := item.Unit
+		if fields.Unit != nil {
+			newUnit = *fields.Unit
+		}
+
+		canonicalUnit, unitErr := validateItemQuantity(newQuantity, newUnit)
+		if unitErr != nil {
+			return nil, unitErr
+		}
+
+		if fields.Quantity != nil {
+			updates["quantity"] = newQuantity
+		}
+		if fields.Unit != nil {
+			updates["unit"] = canonicalUnit
+		}
+	}
+	if fields.Category != nil {
+		updates["category"] = *fields.Category
+	}
+	if fields.IsBought != nil {
+		updates["is_bought"] = *fields.IsBought
 	}
+	if fields.Recurrence != nil {
+		updates["recurrence"] = recurrence
+	}
+	now := time.Now()
+	if fields.IsBought != nil || fields.Recurrence != nil {
+		// a recurring item that is bought comes back once its next occurrence is due
+		newRecurrence := item.Recurrence
+		if fields.Recurrence != nil {
+			newRecurrence = recurrence
+		}
+		newIsBought := item.IsBought
+		if fields.IsBought != nil {
+			newIsBought = *fields.IsBought
+		}
+		updates["recurrence_due_at"] = recurrenceDueAt(item, newRecurrence, newIsBought, now)
+	}
+	updates["version"] = item.Version + 1
 
 	// Update the item
-	err = b.dbPool.GetDB().WithContext(ctx).Model(&item).Updates(updates).Error
-	if err != nil {
-		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to update item.")
+	before := itemActivityValues(item)
+	wasBought := item.IsBought
+	if err := tx.Model(&item).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	if !wasBought && item.IsBought {
+		if err := recordPurchases(tx, userID, []db.ShoplistItem{item}, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordActivity(tx, shoplistID, userID, ActivityItemUpdated, item.ID, before, itemActivityValues(item)); err != nil {
+		return nil, err
 	}
 
 	return &item, nil
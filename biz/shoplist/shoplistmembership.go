@@ -10,15 +10,23 @@ import (
 	"netherealmstudio.com/m/v2/db"
 )
 
-func (b *ShoplistBiz) GetShoplistMembers(ctx context.Context, userID string, shoplistID int) ([]bizmodels.ShoplistMember, *ShoplistError) {
+// Share code expiry bounds
+const (
+	DefaultShareCodeExpiry = 24 * time.Hour
+	MinShareCodeExpiry     = time.Minute
+	MaxShareCodeExpiry     = 30 * 24 * time.Hour
+)
+
+// GetShoplistMembers returns the members of a shoplist and the users with a pending invitation to it
+func (b *ShoplistBiz) GetShoplistMembers(ctx context.Context, userID string, shoplistID int) ([]bizmodels.ShoplistMember, []ShoplistInvitee, *ShoplistError) {
 	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
 	if shopListErr != nil {
-		return nil, shopListErr
+		return nil, nil, shopListErr
 	}
 
 	// check if user is a member
 	if _, exists := shopListData.Members[userID]; !exists {
-		return nil, NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+		return nil, nil, NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
 	}
 
 	result := make([]bizmodels.ShoplistMember, 0)
@@ -26,14 +34,96 @@ func (b *ShoplistBiz) GetShoplistMembers(ctx context.Context, userID string, sho
 		shoplistMember := bizmodels.ShoplistMember{
 			ID:       member.MemberID,
 			Nickname: member.Nickname,
+			Role:     member.Role,
 		}
 		result = append(result, shoplistMember)
 	}
 
-	return result, nil
+	invitees, err := b.getPendingInvitees(ctx, shoplistID)
+	if err != nil {
+		return nil, nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get pending invitees.")
+	}
+
+	return result, invitees, nil
+}
+
+// LeaveShopList removes the user from a shoplist. When the owner leaves, ownership goes to successorID if given,
+// otherwise to the longest-standing member. The last member to leave moves the shoplist to the trash.
+// The shoplist row is locked while the members are read so that members leaving at the same time see each other leave.
+func (b *ShoplistBiz) LeaveShopList(ctx context.Context, userID string, shoplistID int, successorID string) *ShoplistError {
+	var leaveErr *ShoplistError
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var shoplist db.Shoplist
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", shoplistID).First(&shoplist).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				leaveErr = NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+				return leaveErr
+			}
+			return err
+		}
+
+		// The members in the order they joined, the first other member is the default successor
+		var members []db.ShoplistMember
+		if err := tx.Where("shop_list_id = ?", shoplistID).Order("created_at, id").Find(&members).Error; err != nil {
+			return err
+		}
+
+		// check if user is a member
+		isMember := false
+		for _, member := range members {
+			isMember = isMember || member.MemberID == userID
+		}
+		if !isMember {
+			leaveErr = NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+			return leaveErr
+		}
+
+		//If no other members, move the shoplist to the trash
+		if len(members) == 1 {
+			if err := softDeleteShoplist(tx, shoplistID); err != nil {
+				return err
+			}
+
+			return recordActivity(tx, shoplistID, userID, ActivityMemberLeft, 0, nil, nil)
+		}
+
+		//If user is owner, transfer ownership to another member
+		if shoplist.OwnerID == userID {
+			newOwnerID := ""
+			for _, member := range members {
+				if member.MemberID != userID && (successorID == "" || member.MemberID == successorID) {
+					newOwnerID = member.MemberID
+					break
+				}
+			}
+			if newOwnerID == "" {
+				leaveErr = NewShoplistError(ShoplistNewOwnerNotMember, "New owner must be another member of the shoplist.")
+				return leaveErr
+			}
+
+			if err := transferOwnership(tx, shoplistID, userID, newOwnerID); err != nil {
+				return err
+			}
+		}
+
+		// Remove member
+		if err := tx.Where("shop_list_id = ? AND member_id = ?", shoplistID, userID).Delete(&db.ShoplistMember{}).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityMemberLeft, 0, nil, nil)
+	}); err != nil {
+		if leaveErr != nil {
+			return leaveErr
+		}
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to remove member")
+	}
+
+	return nil
 }
 
-func (b *ShoplistBiz) LeaveShopList(ctx context.Context, userID string, shoplistID int) *ShoplistError {
+// TransferShoplistOwnership hands the ownership of a shoplist to another member. Only the owner can transfer ownership.
+func (b *ShoplistBiz) TransferShoplistOwnership(ctx context.Context, userID string, shoplistID int, newOwnerID string) *ShoplistError {
 	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
 	if shopListErr != nil {
 		return shopListErr
@@ -44,76 +134,179 @@ func (b *ShoplistBiz) LeaveShopList(ctx context.Context, userID string, shoplist
 		return NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
 	}
 
-	//If no other members, delete the shoplist
-	if len(shopListData.Members) == 1 {
-		// Use transaction to batch remove member and delete shoplist
-		if err := b.dbPool.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// First remove the member
-			if err := tx.Where("shop_list_id = ? AND member_id = ?", shoplistID, userID).Unscoped().Delete(&db.ShoplistMember{}).Error; err != nil {
-				return err
-			}
+	// check if user is the owner
+	if shopListData.OwnerID != userID {
+		return NewShoplistError(ShoplistNotOwner, "Only the owner can transfer ownership.")
+	}
 
-			// Delete any share code record for the shoplist
-			if err := tx.Where("shop_list_id = ?", shoplistID).Unscoped().Delete(&db.ShoplistShareCode{}).Error; err != nil {
-				return err
-			}
+	// check if the new owner is another member
+	if _, exists := shopListData.Members[newOwnerID]; !exists || newOwnerID == userID {
+		return NewShoplistError(ShoplistNewOwnerNotMember, "New owner must be another member of the shoplist.")
+	}
 
-			// Delete any items for the shoplist
-			if err := tx.Where("shop_list_id = ?", shoplistID).Unscoped().Delete(&db.ShoplistItem{}).Error; err != nil {
-				return err
-			}
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		return transferOwnership(tx, shoplistID, userID, newOwnerID)
+	}); err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to transfer ownership")
+	}
 
-			// Then delete the shoplist
-			if err := tx.Unscoped().Delete(&db.Shoplist{}, shoplistID).Error; err != nil {
-				return err
-			}
+	return nil
+}
 
-			return nil
-		}); err != nil {
-			return NewShoplistError(ShoplistFailedToProcess, "Failed to remove member and delete shoplist")
-		}
-		return nil
+// transferOwnership moves the owner of a shoplist and swaps the stored roles. The previous owner stays on as an editor.
+// The pending invitations of the previous owner are dropped, as only the owner can invite users.
+// gormDB Context already established before calling this function
+func transferOwnership(tx *gorm.DB, shoplistID int, ownerID string, newOwnerID string) error {
+	if err := tx.Model(&db.Shoplist{}).Where("id = ?", shoplistID).Update("owner_id", newOwnerID).Error; err != nil {
+		return err
 	}
 
-	//If user is owner, transfer ownership to another member
-	if shopListData.OwnerID == userID {
-		// Find another member to transfer ownership to
-		var newOwnerID string
-		for memberID := range shopListData.Members {
-			if memberID != userID {
-				newOwnerID = memberID
-				break
-			}
+	if err := tx.Where("shop_list_id = ? AND invited_by = ? AND status = ?", shoplistID, ownerID, InvitationStatusPending).Delete(&db.ShoplistInvitation{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", shoplistID, ownerID).Update("role", MemberRoleEditor).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", shoplistID, newOwnerID).Update("role", MemberRoleOwner).Error; err != nil {
+		return err
+	}
+
+	return recordActivity(tx, shoplistID, ownerID, ActivityOwnershipChanged, 0, activityValues{"owner_id": ownerID}, activityValues{"owner_id": newOwnerID})
+}
+
+// addShoplistMember adds a member to a shoplist. Members that leave or are removed keep their soft-deleted row, which
+// is restored with the new role instead as a member has one row per shoplist.
+// gormDB Context already established before calling this function
+func addShoplistMember(tx *gorm.DB, member *db.ShoplistMember) error {
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"role", "created_at", "updated_at", "deleted_at"}),
+	}).Create(member).Error
+}
+
+// UpdateShoplistMemberRole changes the role of a member. Only the owner can change roles.
+func (b *ShoplistBiz) UpdateShoplistMemberRole(ctx context.Context, userID string, shoplistID int, memberID string, role string) *ShoplistError {
+	if !IsValidAssignableMemberRole(role) {
+		return NewShoplistError(ShoplistInvalidMemberRole, "Role must be editor or viewer.")
+	}
+
+	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
+	if shopListErr != nil {
+		return shopListErr
+	}
+
+	// check if user is a member
+	if _, exists := shopListData.Members[userID]; !exists {
+		return NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+	}
+
+	// check if user is the owner
+	if shopListData.OwnerID != userID {
+		return NewShoplistError(ShoplistNotOwner, "Only the owner can change roles.")
+	}
+
+	if memberID == userID {
+		return NewShoplistError(ShoplistCannotChangeOwnerRole, "Owner role can only change through an ownership transfer.")
+	}
+
+	// check if the target is a member
+	if _, exists := shopListData.Members[memberID]; !exists {
+		return NewShoplistError(ShoplistMemberNotFound, "Member not found.")
+	}
+
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND member_id = ?", shoplistID, memberID).Update("role", role).Error; err != nil {
+			return err
 		}
 
-		// Use transaction to batch transfer ownership and remove member
-		if err := b.dbPool.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// Transfer ownership
-			if err := tx.Model(&db.Shoplist{}).Where("id = ?", shoplistID).Update("owner_id", newOwnerID).Error; err != nil {
-				return err
-			}
+		return recordActivity(tx, shoplistID, userID, ActivityMemberRoleChanged, 0,
+			activityValues{"member_id": memberID, "role": shopListData.Members[memberID].Role},
+			activityValues{"member_id": memberID, "role": role})
+	}); err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to update role")
+	}
 
-			// Remove member
-			if err := tx.Where("shop_list_id = ? AND member_id = ?", shoplistID, userID).Unscoped().Delete(&db.ShoplistMember{}).Error; err != nil {
-				return err
-			}
+	return nil
+}
+
+// RemoveShoplistMember removes another member from a shoplist. Only the owner can remove members.
+// When ban is set, the removed user cannot rejoin the shoplist with any share code.
+func (b *ShoplistBiz) RemoveShoplistMember(ctx context.Context, userID string, shoplistID int, memberID string, ban bool) *ShoplistError {
+	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
+	if shopListErr != nil {
+		return shopListErr
+	}
+
+	// check if user is a member
+	if _, exists := shopListData.Members[userID]; !exists {
+		return NewShoplistError(ShoplistNotMember, "User is not a member of the shoplist.")
+	}
+
+	// check if user is the owner
+	if shopListData.OwnerID != userID {
+		return NewShoplistError(ShoplistNotOwner, "Only the owner can remove members.")
+	}
+
+	if memberID == userID {
+		return NewShoplistError(ShoplistCannotRemoveSelf, "Owner cannot remove themselves.")
+	}
+
+	// check if the target is a member
+	if _, exists := shopListData.Members[memberID]; !exists {
+		return NewShoplistError(ShoplistMemberNotFound, "Member not found.")
+	}
 
+	// Use transaction to batch remove member and ban
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("shop_list_id = ? AND member_id = ?", shoplistID, memberID).Delete(&db.ShoplistMember{}).Error; err != nil {
+			return err
+		}
+
+		if err := recordActivity(tx, shoplistID, userID, ActivityMemberRemoved, 0,
+			activityValues{"member_id": memberID, "role": shopListData.Members[memberID].Role},
+			activityValues{"banned": ban}); err != nil {
+			return err
+		}
+
+		if !ban {
 			return nil
-		}); err != nil {
-			return NewShoplistError(ShoplistFailedToProcess, "Failed to transfer ownership and remove member")
 		}
-		return nil
-	}
 
-	//If user is not owner, remove user from shoplist
-	if err := b.dbPool.GetDB().WithContext(ctx).Where("shop_list_id = ? AND member_id = ?", shoplistID, userID).Unscoped().Delete(&db.ShoplistMember{}).Error; err != nil {
+		banRecord := db.ShoplistBan{
+			ShopListID: shoplistID,
+			MemberID:   memberID,
+			BannedBy:   userID,
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&banRecord).Error
+	}); err != nil {
 		return NewShoplistError(ShoplistFailedToProcess, "Failed to remove member")
 	}
 
 	return nil
 }
 
-func (b *ShoplistBiz) RequestShopListShareCode(ctx context.Context, userID string, shoplistID int) (*db.ShoplistShareCode, *ShoplistError) {
+// RequestShopListShareCode generates a share code that replaces any existing code of the shoplist.
+// A zero expiresIn falls back to DefaultShareCodeExpiry, a zero maxUses means unlimited joins and an empty role grants editor.
+func (b *ShoplistBiz) RequestShopListShareCode(ctx context.Context, userID string, shoplistID int, expiresIn time.Duration, maxUses int, role string) (*db.ShoplistShareCode, *ShoplistError) {
+	if expiresIn == 0 {
+		expiresIn = DefaultShareCodeExpiry
+	}
+	if expiresIn < MinShareCodeExpiry || expiresIn > MaxShareCodeExpiry {
+		return nil, NewShoplistError(ShoplistInvalidShareCodeExpiry, "Share code expiry is out of range.")
+	}
+
+	if maxUses < 0 {
+		return nil, NewShoplistError(ShoplistInvalidShareCodeMaxUses, "Max uses must not be negative.")
+	}
+
+	if role == "" {
+		role = MemberRoleEditor
+	}
+	if !IsValidAssignableMemberRole(role) {
+		return nil, NewShoplistError(ShoplistInvalidMemberRole, "Role must be editor or viewer.")
+	}
+
 	shopListData, shopListErr := b.GetShoplistWithMembers(ctx, shoplistID)
 	if shopListErr != nil {
 		return nil, shopListErr
@@ -129,7 +322,8 @@ func (b *ShoplistBiz) RequestShopListShareCode(ctx context.Context, userID strin
 		return nil, NewShoplistError(ShoplistNotOwner, "Only the owner can generate share codes.")
 	}
 
-	tx := b.dbPool.GetDB().WithContext(ctx).Begin()
+	eventCtx, pendingEvents := collectShoplistEvents(ctx)
+	tx := b.dbPool.GetDB().WithContext(eventCtx).Begin()
 
 	// Generate a share code that is unique among all active share codes (6 characters, alphanumeric)
 	var shareCode string
@@ -139,13 +333,16 @@ func (b *ShoplistBiz) RequestShopListShareCode(ctx context.Context, userID strin
 			break
 		}
 	}
-	expiresAt := time.Now().Add(24 * time.Hour) // Share code expires in 24 hours
+	expiresAt := time.Now().Add(expiresIn)
 
-	// Create or update share code record
+	// Create or update share code record, replacing a previous code also resets its use count
 	shareCodeRecord := db.ShoplistShareCode{
 		ShopListID: shoplistID,
 		Code:       shareCode,
 		Expiry:     expiresAt,
+		MaxUses:    maxUses,
+		UseCount:   0,
+		Role:       role,
 	}
 
 	// Upsert the share code record
@@ -157,10 +354,18 @@ func (b *ShoplistBiz) RequestShopListShareCode(ctx context.Context, userID strin
 		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to generate share code")
 	}
 
+	// The code itself is left out of the activity log as viewers can read it
+	if err := recordActivity(tx, shoplistID, userID, ActivityShareCodeIssued, 0, nil,
+		activityValues{"expires_at": expiresAt.Format(time.RFC3339), "max_uses": maxUses, "role": role}); err != nil {
+		tx.Rollback()
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to generate share code")
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to commit transaction")
 	}
 
+	b.publishShoplistEvents(pendingEvents)
 	return &shareCodeRecord, nil
 }
 
@@ -188,34 +393,117 @@ func (b *ShoplistBiz) RevokeShopListShareCode(ctx context.Context, userID string
 	}
 
 	// Update the expiry to current time to revoke the code
-	if err := b.dbPool.GetDB().WithContext(ctx).Model(&shareCode).Update("expiry", time.Now()).Error; err != nil {
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&shareCode).Update("expiry", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityShareCodeRevoked, 0, nil, nil)
+	}); err != nil {
 		return NewShoplistError(ShoplistFailedToProcess, "Failed to revoke share code")
 	}
 
 	return nil
 }
 
-func (b *ShoplistBiz) JoinShopList(ctx context.Context, userID string, shareCode string) *ShoplistError {
-	var dbShareCode db.ShoplistShareCode
-	err := b.dbPool.GetDB().WithContext(ctx).Where("code = ? AND expiry > ?", shareCode, time.Now()).First(&dbShareCode).Error
+// JoinShopList adds the user to the shoplist of a share code with the role granted by the code.
+// The share code row is locked while joining so that the expiry and the maximum number of uses hold under concurrent joins.
+// Redeeming unknown or expired codes too often locks the user and the client IP out for a while.
+func (b *ShoplistBiz) JoinShopList(ctx context.Context, userID string, clientIP string, shareCode string) *ShoplistError {
+	userKey := userJoinAttemptKey(userID)
+	ipKey := ipJoinAttemptKey(clientIP)
+
+	// The attempt is counted before the code is redeemed so that concurrent guesses are limited too
+	allowed, err := b.reserveJoinAttempt(ctx, userKey, ipKey)
 	if err != nil {
-		return NewShoplistError(ShoplistFailedToProcess, "Invalid share code")
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to check join attempts")
+	}
+	if !allowed {
+		return NewShoplistError(ShoplistTooManyJoinAttempts, "Too many failed attempts to join a shoplist")
 	}
 
-	// Check if user is already a member
-	var existingMember db.ShoplistMember
-	err = b.dbPool.GetDB().WithContext(ctx).Where("shop_list_id = ? AND member_id = ?", dbShareCode.ShopListID, userID).First(&existingMember).Error
-	if err == nil {
-		return NewShoplistError(ShoplistFailedToProcess, "User is already a member of the shoplist")
+	joinErr := b.redeemShareCode(ctx, userID, shareCode)
+	if joinErr != nil && joinErr.ErrCode == ShoplistInvalidShareCode {
+		if err := b.failJoinAttempt(ctx, userKey, ipKey); err != nil {
+			return NewShoplistError(ShoplistFailedToProcess, "Failed to count join attempts")
+		}
+		return joinErr
 	}
 
-	// Add user as member
-	newMember := db.ShoplistMember{
-		ShopListID: dbShareCode.ShopListID,
-		MemberID:   userID,
+	// Only unknown or expired codes count as failures. A successful join clears the failures of the user, the IP
+	// keeps its other failures as other users may share it.
+	if joinErr == nil {
+		err = b.resetJoinAttempts(ctx, userKey)
+	} else {
+		err = b.refundJoinAttempt(ctx, userKey)
+	}
+	if err == nil {
+		err = b.refundJoinAttempt(ctx, ipKey)
+	}
+	if err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to reset join attempts")
 	}
 
-	if err := b.dbPool.GetDB().WithContext(ctx).Create(&newMember).Error; err != nil {
+	return joinErr
+}
+
+// redeemShareCode adds the user to the shoplist of a share code
+func (b *ShoplistBiz) redeemShareCode(ctx context.Context, userID string, shareCode string) *ShoplistError {
+	var joinErr *ShoplistError
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var dbShareCode db.ShoplistShareCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ? AND expiry > ?", shareCode, time.Now()).First(&dbShareCode).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return err
+			}
+			joinErr = NewShoplistError(ShoplistInvalidShareCode, "Invalid share code")
+			return joinErr
+		}
+
+		if dbShareCode.MaxUses > 0 && dbShareCode.UseCount >= dbShareCode.MaxUses {
+			joinErr = NewShoplistError(ShoplistShareCodeExhausted, "Share code has reached its maximum number of uses")
+			return joinErr
+		}
+
+		// Check if user has been banned from the shoplist
+		var banCount int64
+		if err := tx.Model(&db.ShoplistBan{}).Where("shop_list_id = ? AND member_id = ?", dbShareCode.ShopListID, userID).Count(&banCount).Error; err != nil {
+			return err
+		}
+		if banCount > 0 {
+			joinErr = NewShoplistError(ShoplistMemberBanned, "User is banned from the shoplist")
+			return joinErr
+		}
+
+		// Check if user is already a member
+		var existingMember db.ShoplistMember
+		if err := tx.Where("shop_list_id = ? AND member_id = ?", dbShareCode.ShopListID, userID).First(&existingMember).Error; err == nil {
+			joinErr = NewShoplistError(ShoplistFailedToProcess, "User is already a member of the shoplist")
+			return joinErr
+		}
+
+		// Add user as member
+		newMember := db.ShoplistMember{
+			ShopListID: dbShareCode.ShopListID,
+			MemberID:   userID,
+			Role:       dbShareCode.Role,
+		}
+		if err := addShoplistMember(tx, &newMember); err != nil {
+			return err
+		}
+
+		if err := recordActivity(tx, dbShareCode.ShopListID, userID, ActivityMemberJoined, 0, nil,
+			activityValues{"member_id": userID, "role": newMember.Role, "via": "share_code"}); err != nil {
+			return err
+		}
+
+		return tx.Model(&dbShareCode).Update("use_count", gorm.Expr("use_count + 1")).Error
+	})
+
+	if joinErr != nil {
+		return joinErr
+	}
+	if err != nil {
 		return NewShoplistError(ShoplistFailedToProcess, "Failed to join shoplist")
 	}
 
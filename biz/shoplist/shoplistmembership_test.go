@@ -12,7 +12,7 @@ import (
 func TestGetShoplistMembers(t *testing.T) {
 	dbPool := testutil.SetupTestEnv(t)
 	setupSearchTestData(t, dbPool)
-	biz := InitializeShoplistBiz(*dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
 
 	tests := []struct {
 		name            string
@@ -67,13 +67,15 @@ func TestGetShoplistMembers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			members, err := biz.GetShoplistMembers(context.Background(), tt.userID, tt.shoplistID)
+			members, invitees, err := biz.GetShoplistMembers(context.Background(), tt.userID, tt.shoplistID)
 			if tt.expectedError != nil {
 				assert.Equal(t, tt.expectedError.ErrCode, err.ErrCode)
 				assert.Nil(t, members)
+				assert.Nil(t, invitees)
 			} else {
 				assert.Nil(t, err)
 				assert.Equal(t, len(tt.expectedMembers), len(members))
+				assert.Empty(t, invitees)
 
 				// Create a map of expected members for easier comparison
 				expectedMemberMap := make(map[string]bizmodels.ShoplistMember)
@@ -0,0 +1,195 @@
+package bizshoplist
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	bizmodels "netherealmstudio.com/m/v2/biz"
+)
+
+// Ownership filters of the shoplists of a user
+const (
+	ShoplistOwnershipOwned  = "owned"
+	ShoplistOwnershipShared = "shared"
+)
+
+// MaxShoplistPage is the largest page of shoplists
+const MaxShoplistPage = 50
+
+// shoplistCursorPrefix versions the content of a shoplist cursor
+const shoplistCursorPrefix = "s1:"
+
+// ShoplistQuery selects the shoplists of a user to return
+type ShoplistQuery struct {
+	// AfterID continues after the last shoplist of the previous page, 0 for the first page
+	AfterID int
+	// Limit is the number of shoplists on a page, 0 returns all shoplists
+	Limit int
+	// ItemLimit is the number of items returned for every shoplist, 0 returns all items
+	ItemLimit int
+	// Ownership is ShoplistOwnershipOwned or ShoplistOwnershipShared, empty returns both
+	Ownership string
+	// HasUnbought only returns shoplists with at least one item that is not bought
+	HasUnbought bool
+}
+
+// ShoplistPage is a page of the shoplists of a user
+type ShoplistPage struct {
+	Shoplists []*bizmodels.Shoplist
+	// NextCursor continues after the last shoplist of the page, empty when there are no more shoplists
+	NextCursor string
+}
+
+// IsValidShoplistOwnership reports whether the ownership filter is known. An empty filter is valid.
+func IsValidShoplistOwnership(ownership string) bool {
+	return ownership == "" || ownership == ShoplistOwnershipOwned || ownership == ShoplistOwnershipShared
+}
+
+// EncodeShoplistCursor returns the opaque cursor of a page that continues after the shoplist
+func EncodeShoplistCursor(shoplistID int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(shoplistCursorPrefix + strconv.Itoa(shoplistID)))
+}
+
+// DecodeShoplistCursor returns the shoplist a page continues after
+func DecodeShoplistCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	id, found := strings.CutPrefix(string(decoded), shoplistCursorPrefix)
+	if !found {
+		return 0, errors.New("unknown shoplist cursor")
+	}
+
+	shoplistID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, err
+	}
+	if shoplistID <= 0 {
+		return 0, errors.New("shoplist cursor out of range")
+	}
+
+	return shoplistID, nil
+}
+
+// GetShoplistPageForUser returns a page of the shoplists the user is a member of with their items, ordered by ID.
+// Templates are left out. The pages are keyed on the shoplist ID, so shoplists that are added or removed between
+// requests do not shift the following pages.
+func (b *ShoplistBiz) GetShoplistPageForUser(ctx context.Context, userID string, query ShoplistQuery) (*ShoplistPage, *ShoplistError) {
+	if query.AfterID < 0 || query.Limit < 0 || query.Limit > MaxShoplistPage || query.ItemLimit < 0 || !IsValidShoplistOwnership(query.Ownership) {
+		return nil, NewShoplistError(ShoplistInvalidQuery, "Invalid shoplist query.")
+	}
+
+	gormDB := b.dbPool.GetDB().WithContext(ctx)
+
+	shoplistQuery := gormDB.Table("shoplist_members").
+		Select(`shoplists.id as id, shoplists.name as name, shoplists.owner_id as owner_id, users.nickname as owner_nickname,
+			shoplists.version as version`).
+		Joins("JOIN shoplists ON shoplist_members.shop_list_id = shoplists.id AND shoplists.deleted_at IS NULL AND shoplists.is_template = false").
+		Joins("LEFT JOIN users ON shoplists.owner_id = users.id").
+		Where("shoplist_members.member_id = ? AND shoplist_members.deleted_at IS NULL AND shoplists.id > ?", userID, query.AfterID)
+	switch query.Ownership {
+	case ShoplistOwnershipOwned:
+		shoplistQuery = shoplistQuery.Where("shoplists.owner_id = ?", userID)
+	case ShoplistOwnershipShared:
+		shoplistQuery = shoplistQuery.Where("shoplists.owner_id <> ?", userID)
+	}
+	if query.HasUnbought {
+		shoplistQuery = shoplistQuery.Where(`EXISTS (SELECT 1 FROM shoplist_items
+			WHERE shoplist_items.shop_list_id = shoplists.id AND shoplist_items.is_bought = false AND shoplist_items.deleted_at IS NULL)`)
+	}
+	shoplistQuery = shoplistQuery.Order("shoplists.id")
+	if query.Limit > 0 {
+		// one more than the page to know whether there is a next page
+		shoplistQuery = shoplistQuery.Limit(query.Limit + 1)
+	}
+
+	var shoplistRows []struct {
+		ID            int
+		Name          string
+		OwnerID       string
+		OwnerNickname string
+		Version       int
+	}
+	if err := shoplistQuery.Scan(&shoplistRows).Error; err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplists.")
+	}
+
+	page := &ShoplistPage{Shoplists: make([]*bizmodels.Shoplist, 0, len(shoplistRows))}
+	if query.Limit > 0 && len(shoplistRows) > query.Limit {
+		shoplistRows = shoplistRows[:query.Limit]
+		page.NextCursor = EncodeShoplistCursor(shoplistRows[query.Limit-1].ID)
+	}
+
+	if len(shoplistRows) == 0 {
+		return page, nil
+	}
+
+	shoplistByID := make(map[int]*bizmodels.Shoplist, len(shoplistRows))
+	shoplistIDs := make([]int, 0, len(shoplistRows))
+	for _, r := range shoplistRows {
+		shoplist := &bizmodels.Shoplist{
+			ID:            r.ID,
+			Name:          r.Name,
+			OwnerID:       r.OwnerID,
+			OwnerNickname: r.OwnerNickname,
+			Version:       r.Version,
+			Items:         make([]bizmodels.ShoplistItem, 0),
+		}
+		page.Shoplists = append(page.Shoplists, shoplist)
+		shoplistByID[shoplist.ID] = shoplist
+		shoplistIDs = append(shoplistIDs, shoplist.ID)
+	}
+
+	// Number the items of every shoplist in list order so that only the first ItemLimit items are read
+	var itemRows []struct {
+		ID         int
+		ShopListID int
+		ItemName   string
+		BrandName  string
+		ExtraInfo  string
+		Quantity   float64
+		Unit       string
+		Category   string
+		IsBought   bool
+		Version    int
+		Recurrence string
+		ItemCount  int
+	}
+	err := gormDB.Raw(`SELECT id, shop_list_id, item_name, brand_name, extra_info, quantity, unit, category, is_bought, version, recurrence, item_count
+		FROM (
+			SELECT shoplist_items.*, ROW_NUMBER() OVER (PARTITION BY shop_list_id ORDER BY position, id) as row_num,
+				COUNT(*) OVER (PARTITION BY shop_list_id) as item_count
+			FROM shoplist_items
+			WHERE shop_list_id IN ? AND deleted_at IS NULL
+		) as numbered_items
+		WHERE ? = 0 OR row_num <= ?
+		ORDER BY shop_list_id, row_num`, shoplistIDs, query.ItemLimit, query.ItemLimit).Scan(&itemRows).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplist items.")
+	}
+
+	for _, r := range itemRows {
+		shoplist := shoplistByID[r.ShopListID]
+		shoplist.Items = append(shoplist.Items, bizmodels.ShoplistItem{
+			ID:         r.ID,
+			ShopListID: r.ShopListID,
+			ItemName:   r.ItemName,
+			BrandName:  r.BrandName,
+			ExtraInfo:  r.ExtraInfo,
+			Quantity:   r.Quantity,
+			Unit:       r.Unit,
+			Category:   r.Category,
+			IsBought:   r.IsBought,
+			Version:    r.Version,
+			Recurrence: r.Recurrence,
+		})
+		shoplist.HasMoreItems = r.ItemCount > len(shoplist.Items)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,18 @@
+package bizshoplist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShoplistCursor(t *testing.T) {
+	decoded, err := DecodeShoplistCursor(EncodeShoplistCursor(42))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, decoded)
+
+	for _, cursor := range []string{"not a cursor", "NDI", "czE6", "czE6YWJj", "czE6MA", "czE6LTE"} {
+		_, err := DecodeShoplistCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
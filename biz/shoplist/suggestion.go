@@ -0,0 +1,133 @@
+package bizshoplist
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item suggestion page sizes
+const (
+	DefaultItemSuggestions = 10
+	MaxItemSuggestions     = 50
+
+	// maxSuggestionCandidates bounds the number of distinct items ranked for a request
+	maxSuggestionCandidates = 200
+	// suggestionListWeight is how much more an item counts when it was used in the shoplist itself
+	suggestionListWeight = 2
+	// suggestionRecencyDays is the age in days at which the score of an item is halved
+	suggestionRecencyDays = 30
+)
+
+// GetItemSuggestions returns items to suggest when adding to a shoplist, best first. Items bought or added before in
+// the shoplist and in the other shoplists of the user are ranked by how often and how recently they were used. Only
+// items whose name starts with the prefix are returned, and items on the shoplist that are not bought yet are left out.
+// The user must be a member of the shoplist.
+func (b *ShoplistBiz) GetItemSuggestions(ctx context.Context, userID string, shoplistID int, prefix string, limit int) ([]ItemSuggestion, *ShoplistError) {
+	if _, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID); !isMember {
+		return nil, NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	if limit <= 0 {
+		limit = DefaultItemSuggestions
+	}
+	if limit > MaxItemSuggestions {
+		limit = MaxItemSuggestions
+	}
+
+	gormDB := b.dbPool.GetDB().WithContext(ctx)
+	pattern := escapeLike(strings.TrimSpace(prefix)) + "%"
+	memberShoplists := gormDB.Table("shoplist_members").Select("shop_list_id").Where("member_id = ? AND deleted_at IS NULL", userID)
+
+	// Past items include the items that were removed since, purchases are kept after their shoplist is removed
+	candidates := make([]ItemSuggestion, 0)
+	err := gormDB.Raw(`SELECT MIN(item_name) as item_name, MIN(brand_name) as brand_name, MAX(unit) as unit,
+			MAX(category) as category, SUM(in_shoplist) as shoplist_count, COUNT(*) - SUM(in_shoplist) as other_count,
+			MAX(used_at) as last_used_at
+		FROM (
+			SELECT item_name, brand_name, unit, category, shop_list_id = ? as in_shoplist, purchased_at as used_at
+			FROM shoplist_purchases
+			WHERE deleted_at IS NULL AND (buyer_id = ? OR shop_list_id IN (?)) AND item_name LIKE ?
+			UNION ALL
+			SELECT item_name, brand_name, unit, category, shop_list_id = ? as in_shoplist, created_at as used_at
+			FROM shoplist_items
+			WHERE shop_list_id IN (?) AND item_name LIKE ?
+		) AS candidates
+		GROUP BY LOWER(item_name), LOWER(brand_name)
+		ORDER BY COUNT(*) DESC, MAX(used_at) DESC
+		LIMIT ?`,
+		shoplistID, userID, memberShoplists, pattern,
+		shoplistID, memberShoplists, pattern,
+		maxSuggestionCandidates).Scan(&candidates).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get item suggestions.")
+	}
+
+	var pending []struct {
+		ItemName  string
+		BrandName string
+	}
+	if err := gormDB.Table("shoplist_items").Select("item_name, brand_name").
+		Where("shop_list_id = ? AND is_bought = ? AND deleted_at IS NULL", shoplistID, false).
+		Scan(&pending).Error; err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get item suggestions.")
+	}
+	onShoplist := make(map[string]bool, len(pending))
+	for _, item := range pending {
+		onShoplist[suggestionKey(item.ItemName, item.BrandName)] = true
+	}
+
+	suggestions := make([]ItemSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !onShoplist[suggestionKey(candidate.ItemName, candidate.BrandName)] {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return rankItemSuggestions(suggestions, time.Now(), limit), nil
+}
+
+// suggestionKey identifies an item regardless of case
+func suggestionKey(itemName string, brandName string) string {
+	return strings.ToLower(itemName) + "\x00" + strings.ToLower(brandName)
+}
+
+// suggestionScore weighs how often an item was used, uses in the shoplist itself counting more, by how long ago it
+// was last used
+func suggestionScore(shoplistCount int, otherCount int, lastUsedAt time.Time, now time.Time) float64 {
+	ageDays := now.Sub(lastUsedAt).Hours() / 24
+	if ageDays < 0 {
+		ageDays = 0
+	}
+	frequency := float64(suggestionListWeight*shoplistCount + otherCount)
+	return frequency / (1 + ageDays/suggestionRecencyDays)
+}
+
+// rankItemSuggestions scores the suggestions and returns the best ones first. Ties go to the most recently used item,
+// then to the name.
+func rankItemSuggestions(suggestions []ItemSuggestion, now time.Time, limit int) []ItemSuggestion {
+	for i := range suggestions {
+		suggestions[i].Score = suggestionScore(suggestions[i].ShoplistCount, suggestions[i].OtherCount, suggestions[i].LastUsedAt, now)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if !suggestions[i].LastUsedAt.Equal(suggestions[j].LastUsedAt) {
+			return suggestions[i].LastUsedAt.After(suggestions[j].LastUsedAt)
+		}
+		return strings.ToLower(suggestions[i].ItemName) < strings.ToLower(suggestions[j].ItemName)
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// escapeLike escapes the wildcards of a value used in a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,91 @@
+package bizshoplist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	dbmodel "netherealmstudio.com/m/v2/db"
+	testutil "netherealmstudio.com/m/v2/testUtil"
+)
+
+func TestRankItemSuggestions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	suggestions := []ItemSuggestion{
+		{ItemName: "Eggs", OtherCount: 4, LastUsedAt: now.AddDate(0, 0, -90)},
+		{ItemName: "Milk", ShoplistCount: 2, LastUsedAt: now.AddDate(0, 0, -1)},
+		{ItemName: "Bread", OtherCount: 2, LastUsedAt: now.AddDate(0, 0, -1)},
+		{ItemName: "apples", OtherCount: 2, LastUsedAt: now.AddDate(0, 0, -1)},
+		{ItemName: "Butter", OtherCount: 2, LastUsedAt: now},
+	}
+
+	ranked := rankItemSuggestions(suggestions, now, 4)
+	names := make([]string, 0, len(ranked))
+	for _, suggestion := range ranked {
+		names = append(names, suggestion.ItemName)
+	}
+
+	// Uses in the shoplist count double, old uses fade and ties go to the latest then to the name
+	assert.Equal(t, []string{"Milk", "Butter", "apples", "Bread"}, names)
+	assert.Equal(t, 4.0, suggestionScore(2, 0, now, now))
+	assert.Equal(t, 1.0, suggestionScore(0, 4, now.AddDate(0, 0, -90), now))
+	assert.Equal(t, 2.0, suggestionScore(0, 2, now.Add(time.Hour), now))
+}
+
+func TestGetItemSuggestions(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+	ctx := context.Background()
+
+	// Item 1 was bought twice before in shoplist 1, Item 6 once by the user in another shoplist
+	purchases := []dbmodel.ShoplistPurchase{
+		{ShopListID: 1, ItemID: 1, ItemName: "Item 1", BrandName: "Brand 1", BuyerID: "test_user", PurchasedAt: time.Now().AddDate(0, 0, -7)},
+		{ShopListID: 1, ItemID: 1, ItemName: "Item 1", BrandName: "Brand 1", BuyerID: "test_user", PurchasedAt: time.Now().AddDate(0, 0, -14)},
+		{ShopListID: 99, ItemID: 99, ItemName: "Item 6", BrandName: "Brand 6", BuyerID: "test_user", PurchasedAt: time.Now()},
+		{ShopListID: 2, ItemID: 4, ItemName: "Item 4", BrandName: "Brand 4", BuyerID: "test_user2", PurchasedAt: time.Now()},
+	}
+	assert.NoError(t, dbPool.GetDB().Create(&purchases).Error)
+
+	suggestions, shoplistErr := biz.GetItemSuggestions(ctx, "test_user", 1, "item", 0)
+	assert.Nil(t, shoplistErr)
+	names := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		names = append(names, suggestion.ItemName)
+	}
+
+	// Items 1 and 3 are on the shoplist and not bought, item 4 is in a shoplist of another user
+	assert.Contains(t, names, "Item 2")
+	assert.Contains(t, names, "Item 6")
+	assert.NotContains(t, names, "Item 1")
+	assert.NotContains(t, names, "Item 3")
+	assert.NotContains(t, names, "Item 4")
+
+	// The prefix narrows down the suggestions, wildcards are matched literally
+	suggestions, shoplistErr = biz.GetItemSuggestions(ctx, "test_user", 1, "Item 6", 0)
+	assert.Nil(t, shoplistErr)
+	if assert.NotEmpty(t, suggestions) {
+		assert.Equal(t, "Item 6", suggestions[0].ItemName)
+	}
+
+	suggestions, shoplistErr = biz.GetItemSuggestions(ctx, "test_user", 1, "%", 0)
+	assert.Nil(t, shoplistErr)
+	assert.Empty(t, suggestions)
+
+	// Once bought, item 1 is suggested first as the most used item of the shoplist
+	isBought := true
+	_, shoplistErr = biz.UpdateShoplistItemFields(ctx, "test_user", 1, 1, ShoplistItemUpdate{IsBought: &isBought}, AnyVersion)
+	assert.Nil(t, shoplistErr)
+
+	suggestions, shoplistErr = biz.GetItemSuggestions(ctx, "test_user", 1, "", 1)
+	assert.Nil(t, shoplistErr)
+	if assert.Len(t, suggestions, 1) {
+		assert.Equal(t, "Item 1", suggestions[0].ItemName)
+		assert.Equal(t, 4, suggestions[0].ShoplistCount)
+	}
+
+	_, shoplistErr = biz.GetItemSuggestions(ctx, "test_user2", 1, "", 0)
+	assert.Equal(t, ShoplistNotFound, shoplistErr.ErrCode)
+}
@@ -0,0 +1,184 @@
+package bizshoplist
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// syncCursorOverlap moves a new sync cursor back in time so that changes committed while a sync was running, or
+// stamped by an instance with a slightly different clock, are returned again by the next sync instead of being missed.
+// Clients apply the changes as upserts, so seeing a change twice is harmless.
+const syncCursorOverlap = 5 * time.Second
+
+// EncodeSyncCursor returns the opaque cursor of a sync that continues from t
+func EncodeSyncCursor(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(t.UnixMilli(), 10)))
+}
+
+// DecodeSyncCursor returns the time a sync cursor continues from
+func DecodeSyncCursor(cursor string) (time.Time, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	millis, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if millis <= 0 {
+		return time.Time{}, errors.New("sync cursor out of range")
+	}
+
+	return time.UnixMilli(millis), nil
+}
+
+// SyncShoplists returns the shoplists, items and memberships of a user that were created, changed or deleted after
+// since, including tombstones for the removals. A zero since returns the full state. When since is older than the
+// trash retention the tombstones may have been purged already, so the full state is returned as well.
+func (b *ShoplistBiz) SyncShoplists(ctx context.Context, userID string, since time.Time) (*ShoplistSync, *ShoplistError) {
+	syncStart := time.Now()
+	if b.trashRetention > 0 && since.Before(syncStart.Add(-b.trashRetention)) {
+		since = time.Time{}
+	}
+
+	changes := &ShoplistSync{
+		Cursor:             EncodeSyncCursor(syncStart.Add(-syncCursorOverlap)),
+		Reset:              since.IsZero(),
+		Shoplists:          make([]SyncedShoplist, 0),
+		DeletedShoplistIDs: make([]int, 0),
+		Items:              make([]SyncedShoplistItem, 0),
+		DeletedItems:       make([]ShoplistItemTombstone, 0),
+		Members:            make([]SyncedShoplistMember, 0),
+		DeletedMembers:     make([]ShoplistMemberTombstone, 0),
+	}
+
+	gormDB := b.dbPool.GetDB().WithContext(ctx)
+
+	// The shoplists the user is a member of. Shoplists the user joined, or got back from the trash, since the cursor
+	// are returned in full as the client has no copy of them.
+	var memberships []struct {
+		ShopListID int       `gorm:"column:shop_list_id"`
+		UpdatedAt  time.Time `gorm:"column:updated_at"`
+	}
+	err := gormDB.Raw(`SELECT shoplist_members.shop_list_id as shop_list_id, shoplist_members.updated_at as updated_at FROM shoplist_members
+		JOIN shoplists ON shoplists.id = shoplist_members.shop_list_id AND shoplists.deleted_at IS NULL
+		WHERE shoplist_members.member_id = ? AND shoplist_members.deleted_at IS NULL`, userID).Scan(&memberships).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplists.")
+	}
+
+	shoplistIDs := make([]int, 0, len(memberships))
+	fullShoplistIDs := make([]int, 0)
+	for _, membership := range memberships {
+		shoplistIDs = append(shoplistIDs, membership.ShopListID)
+		if membership.UpdatedAt.After(since) {
+			fullShoplistIDs = append(fullShoplistIDs, membership.ShopListID)
+		}
+	}
+
+	// A reset replaces the local copy, so it needs no tombstones
+	if !changes.Reset {
+		deletedShoplistIDs, err := b.getLostShoplistIDs(gormDB, userID, since, shoplistIDs)
+		if err != nil {
+			return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get deleted shoplists.")
+		}
+		changes.DeletedShoplistIDs = deletedShoplistIDs
+	}
+
+	if len(shoplistIDs) == 0 {
+		return changes, nil
+	}
+
+	err = gormDB.Raw(`SELECT shoplists.id as id, shoplists.name as name, shoplists.owner_id as owner_id, users.nickname as owner_nickname,
+			shoplists.version as version, shoplists.is_template as is_template, shoplists.updated_at as updated_at
+		FROM shoplists
+		LEFT JOIN users ON shoplists.owner_id = users.id
+		WHERE shoplists.id IN ? AND (shoplists.updated_at > ? OR shoplists.id IN ?)
+		ORDER BY shoplists.id`, shoplistIDs, since, fullShoplistIDs).Scan(&changes.Shoplists).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get shoplists.")
+	}
+
+	err = gormDB.Raw(`SELECT id, shop_list_id, item_name, brand_name, extra_info, quantity, unit, category, is_bought, position, thumbnail, version, recurrence, updated_at
+		FROM shoplist_items
+		WHERE shop_list_id IN ? AND deleted_at IS NULL AND (updated_at > ? OR shop_list_id IN ?)
+		ORDER BY shop_list_id, position, id`, shoplistIDs, since, fullShoplistIDs).Scan(&changes.Items).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get items.")
+	}
+
+	if !changes.Reset {
+		err = gormDB.Raw(`SELECT id, shop_list_id FROM shoplist_items
+			WHERE shop_list_id IN ? AND deleted_at > ?
+			ORDER BY shop_list_id, id`, shoplistIDs, since).Scan(&changes.DeletedItems).Error
+		if err != nil {
+			return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get deleted items.")
+		}
+	}
+
+	var members []struct {
+		SyncedShoplistMember
+		OwnerID string `gorm:"column:owner_id"`
+	}
+	err = gormDB.Raw(`SELECT shoplist_members.shop_list_id as shop_list_id, shoplist_members.member_id as member_id, users.nickname as nickname,
+			shoplist_members.role as role, shoplists.owner_id as owner_id
+		FROM shoplist_members
+		JOIN shoplists ON shoplists.id = shoplist_members.shop_list_id
+		LEFT JOIN users ON shoplist_members.member_id = users.id
+		WHERE shoplist_members.shop_list_id IN ? AND shoplist_members.deleted_at IS NULL
+			AND (shoplist_members.updated_at > ? OR shoplist_members.shop_list_id IN ?)
+		ORDER BY shoplist_members.shop_list_id, shoplist_members.id`, shoplistIDs, since, fullShoplistIDs).Scan(&members).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get members.")
+	}
+	for _, member := range members {
+		member.Role = effectiveMemberRole(member.OwnerID, member.MemberID, member.Role)
+		changes.Members = append(changes.Members, member.SyncedShoplistMember)
+	}
+
+	if !changes.Reset {
+		err = gormDB.Raw(`SELECT shop_list_id, member_id FROM shoplist_members
+			WHERE shop_list_id IN ? AND deleted_at > ?
+			ORDER BY shop_list_id, id`, shoplistIDs, since).Scan(&changes.DeletedMembers).Error
+		if err != nil {
+			return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get deleted members.")
+		}
+	}
+
+	return changes, nil
+}
+
+// getLostShoplistIDs returns the shoplists the user lost access to since the cursor, because the shoplist was deleted
+// or the user left or was removed. Shoplists in currentIDs are left out as the user is a member again.
+// gormDB Context already established before calling this function
+func (b *ShoplistBiz) getLostShoplistIDs(gormDB *gorm.DB, userID string, since time.Time, currentIDs []int) ([]int, error) {
+	// Members that leave or are removed, and the members of deleted shoplists, are soft-deleted
+	var lostIDs []int
+	if err := gormDB.Unscoped().Model(&db.ShoplistMember{}).Where("member_id = ? AND deleted_at > ?", userID, since).
+		Pluck("shop_list_id", &lostIDs).Error; err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[int]bool, len(currentIDs))
+	for _, id := range currentIDs {
+		excluded[id] = true
+	}
+
+	deletedIDs := make([]int, 0, len(lostIDs))
+	for _, id := range lostIDs {
+		if !excluded[id] {
+			excluded[id] = true
+			deletedIDs = append(deletedIDs, id)
+		}
+	}
+	sort.Ints(deletedIDs)
+	return deletedIDs, nil
+}
@@ -0,0 +1,21 @@
+package bizshoplist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncCursor(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	decoded, err := DecodeSyncCursor(EncodeSyncCursor(now))
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(decoded))
+
+	for _, cursor := range []string{"not a cursor", "YWJj", "MA", "LTE"} {
+		_, err := DecodeSyncCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
@@ -0,0 +1,197 @@
+package bizshoplist
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// CloneShoplistOptions selects what is copied when a shoplist is cloned
+type CloneShoplistOptions struct {
+	// Name of the new shoplist, empty keeps the name of the source shoplist
+	Name string
+	// ResetBought copies the items as not bought
+	ResetBought bool
+	// IncludeMembers copies the other members of the source shoplist with their roles. Only the owner of the source
+	// shoplist may copy its members.
+	IncludeMembers bool
+	// AsTemplate saves the new shoplist as a template
+	AsTemplate bool
+}
+
+// CloneShoplist copies a shoplist the user is a member of into a new shoplist owned by the user and returns the ID
+// of the new shoplist. The items are copied in their order, the members only when requested by the owner of the
+// shoplist.
+func (b *ShoplistBiz) CloneShoplist(ctx context.Context, userID string, shoplistID int, options CloneShoplistOptions) (int, *ShoplistError) {
+	return b.cloneShoplist(ctx, userID, shoplistID, false, options)
+}
+
+// SaveShoplistAsTemplate saves a copy of a shoplist the user is a member of as a template owned by the user and
+// returns the ID of the template. The items of a template are never bought.
+func (b *ShoplistBiz) SaveShoplistAsTemplate(ctx context.Context, userID string, shoplistID int, name string) (int, *ShoplistError) {
+	return b.cloneShoplist(ctx, userID, shoplistID, false, CloneShoplistOptions{
+		Name:        name,
+		ResetBought: true,
+		AsTemplate:  true,
+	})
+}
+
+// InstantiateShoplistTemplate creates a new shoplist owned by the user from a template the user is a member of and
+// returns the ID of the new shoplist
+func (b *ShoplistBiz) InstantiateShoplistTemplate(ctx context.Context, userID string, templateID int, name string) (int, *ShoplistError) {
+	return b.cloneShoplist(ctx, userID, templateID, true, CloneShoplistOptions{
+		Name:        name,
+		ResetBought: true,
+	})
+}
+
+// cloneShoplist copies the source shoplist, its items and optionally its members in one transaction. With
+// templateOnly the source shoplist must be a template.
+func (b *ShoplistBiz) cloneShoplist(ctx context.Context, userID string, sourceID int, templateOnly bool, options CloneShoplistOptions) (int, *ShoplistError) {
+	var clone db.Shoplist
+	err := b.transaction(ctx, func(tx *gorm.DB) error {
+		var source db.Shoplist
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+			}
+			return err
+		}
+		if templateOnly && !source.IsTemplate {
+			return NewShoplistError(ShoplistNotFound, "Template not found.")
+		}
+
+		// Shoplists of other users are reported as not found, like for reads
+		var membership db.ShoplistMember
+		if err := tx.Where("shop_list_id = ? AND member_id = ?", sourceID, userID).First(&membership).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+			}
+			return err
+		}
+
+		// Members only join a shoplist through its owner
+		if options.IncludeMembers && source.OwnerID != userID {
+			return NewShoplistError(ShoplistNotOwner, "Only the owner can copy the members of a shoplist.")
+		}
+
+		clone = db.Shoplist{
+			OwnerID:    userID,
+			Name:       options.Name,
+			IsTemplate: options.AsTemplate,
+		}
+		if clone.Name == "" {
+			clone.Name = source.Name
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&db.ShoplistMember{ShopListID: clone.ID, MemberID: userID, Role: MemberRoleOwner}).Error; err != nil {
+			return err
+		}
+
+		if err := copyShoplistItems(tx, sourceID, clone.ID, options.ResetBought); err != nil {
+			return err
+		}
+
+		if options.IncludeMembers {
+			if err := copyShoplistMembers(tx, sourceID, clone.ID, userID); err != nil {
+				return err
+			}
+		}
+
+		return recordActivity(tx, clone.ID, userID, ActivityShoplistCloned, 0, nil, activityValues{
+			"name":        clone.Name,
+			"source_id":   sourceID,
+			"is_template": clone.IsTemplate,
+		})
+	})
+	if err != nil {
+		if shoplistErr, ok := err.(*ShoplistError); ok {
+			return 0, shoplistErr
+		}
+		return 0, NewShoplistError(ShoplistFailedToCreate, "Failed to clone shoplist.")
+	}
+
+	return clone.ID, nil
+}
+
+// copyShoplistItems copies the items of a shoplist to another shoplist, keeping their order
+// gormDB Context already established before calling this function
+func copyShoplistItems(tx *gorm.DB, sourceID int, cloneID int, resetBought bool) error {
+	var items []db.ShoplistItem
+	if err := tx.Where("shop_list_id = ?", sourceID).Order("position, id").Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	clonedItems := make([]db.ShoplistItem, 0, len(items))
+	for _, item := range items {
+		isBought := item.IsBought && !resetBought
+		clonedItems = append(clonedItems, db.ShoplistItem{
+			ShopListID: cloneID,
+			ItemName:   item.ItemName,
+			BrandName:  item.BrandName,
+			ExtraInfo:  item.ExtraInfo,
+			Quantity:   item.Quantity,
+			Unit:       item.Unit,
+			Category:   item.Category,
+			IsBought:   isBought,
+			Position:   item.Position,
+			Thumbnail:  item.Thumbnail,
+			Recurrence: item.Recurrence,
+			// a copy that stays bought comes back with the source item, any other copy once it is bought
+			RecurrenceDueAt: recurrenceDueAt(item, item.Recurrence, isBought, now),
+		})
+	}
+
+	return tx.Create(&clonedItems).Error
+}
+
+// copyShoplistMembers copies the members of a shoplist other than the new owner to another shoplist with their roles
+// gormDB Context already established before calling this function
+func copyShoplistMembers(tx *gorm.DB, sourceID int, cloneID int, ownerID string) error {
+	var members []db.ShoplistMember
+	if err := tx.Where("shop_list_id = ? AND member_id <> ?", sourceID, ownerID).Order("id").Find(&members).Error; err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	clonedMembers := make([]db.ShoplistMember, 0, len(members))
+	for _, member := range members {
+		clonedMembers = append(clonedMembers, db.ShoplistMember{
+			ShopListID: cloneID,
+			MemberID:   member.MemberID,
+			Role:       member.Role,
+		})
+	}
+
+	return tx.Create(&clonedMembers).Error
+}
+
+// GetShoplistTemplatesForUser returns the templates the user is a member of, ordered by ID
+func (b *ShoplistBiz) GetShoplistTemplatesForUser(ctx context.Context, userID string) ([]ShoplistTemplate, *ShoplistError) {
+	templates := make([]ShoplistTemplate, 0)
+	err := b.dbPool.GetDB().WithContext(ctx).Raw(`SELECT shoplists.id as id, shoplists.name as name, shoplists.owner_id as owner_id,
+			users.nickname as owner_nickname,
+			(SELECT COUNT(*) FROM shoplist_items WHERE shoplist_items.shop_list_id = shoplists.id AND shoplist_items.deleted_at IS NULL) as item_count
+		FROM shoplist_members
+		JOIN shoplists ON shoplist_members.shop_list_id = shoplists.id AND shoplists.deleted_at IS NULL AND shoplists.is_template = true
+		LEFT JOIN users ON shoplists.owner_id = users.id
+		WHERE shoplist_members.member_id = ? AND shoplist_members.deleted_at IS NULL
+		ORDER BY shoplists.id`, userID).Scan(&templates).Error
+	if err != nil {
+		return nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get templates.")
+	}
+
+	return templates, nil
+}
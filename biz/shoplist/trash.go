@@ -0,0 +1,198 @@
+package bizshoplist
+
+import (
+	"context"
+	"time"
+
+	"github.com/kdjuwidja/aishoppercommon/logger"
+	"gorm.io/gorm"
+	"netherealmstudio.com/m/v2/db"
+)
+
+// softDeleteShoplist moves a shoplist to the trash together with its members and items.
+// All rows share the same deletion time so that a restore can bring back exactly the rows removed with the list.
+// Share codes are not kept.
+// gormDB Context already established before calling this function
+func softDeleteShoplist(tx *gorm.DB, shoplistID int) error {
+	deletedAt := time.Now().Truncate(time.Millisecond)
+
+	// First remove the members
+	if err := tx.Model(&db.ShoplistMember{}).Where("shop_list_id = ?", shoplistID).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+
+	// Delete any share code record for the shoplist
+	if err := tx.Where("shop_list_id = ?", shoplistID).Unscoped().Delete(&db.ShoplistShareCode{}).Error; err != nil {
+		return err
+	}
+
+	// Delete any items for the shoplist
+	if err := tx.Model(&db.ShoplistItem{}).Where("shop_list_id = ?", shoplistID).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+
+	// Then delete the shoplist
+	return tx.Model(&db.Shoplist{}).Where("id = ?", shoplistID).Update("deleted_at", deletedAt).Error
+}
+
+// GetTrash returns the deleted shoplists owned by the user and the deleted items of the shoplists the user is a member of
+func (b *ShoplistBiz) GetTrash(ctx context.Context, userID string) ([]TrashedShoplist, []TrashedShoplistItem, *ShoplistError) {
+	shoplists := make([]TrashedShoplist, 0)
+	err := b.dbPool.GetDB().WithContext(ctx).Raw(`
+		SELECT id, name, deleted_at FROM shoplists
+		WHERE owner_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id`, userID).Scan(&shoplists).Error
+	if err != nil {
+		return nil, nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get deleted shoplists.")
+	}
+
+	items := make([]TrashedShoplistItem, 0)
+	err = b.dbPool.GetDB().WithContext(ctx).Raw(`
+		SELECT shoplist_items.id as id, shoplist_items.shop_list_id as shop_list_id, shoplists.name as shop_list_name,
+			item_name, brand_name, shoplist_items.deleted_at as deleted_at
+		FROM shoplist_items
+		JOIN shoplists ON shoplist_items.shop_list_id = shoplists.id AND shoplists.deleted_at IS NULL
+		JOIN shoplist_members ON shoplist_members.shop_list_id = shoplists.id AND shoplist_members.deleted_at IS NULL
+		WHERE shoplist_members.member_id = ? AND shoplist_items.deleted_at IS NOT NULL
+		ORDER BY shoplist_items.deleted_at DESC, shoplist_items.id`, userID).Scan(&items).Error
+	if err != nil {
+		return nil, nil, NewShoplistError(ShoplistFailedToProcess, "Failed to get deleted items.")
+	}
+
+	return shoplists, items, nil
+}
+
+// RestoreShoplist brings a deleted shoplist back together with the members and items that were deleted with it.
+// Only the owner can restore a shoplist.
+func (b *ShoplistBiz) RestoreShoplist(ctx context.Context, userID string, shoplistID int) *ShoplistError {
+	var shoplist db.Shoplist
+	err := b.dbPool.GetDB().WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", shoplistID).First(&shoplist).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+		}
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to check shoplist.")
+	}
+
+	// check if user is the owner
+	if shoplist.OwnerID != userID {
+		return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	deletedAt := shoplist.DeletedAt.Time
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&db.ShoplistMember{}).Where("shop_list_id = ? AND deleted_at = ?", shoplistID, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&db.ShoplistItem{}).Where("shop_list_id = ? AND deleted_at = ?", shoplistID, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&db.Shoplist{}).Where("id = ?", shoplistID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityShoplistRestored, 0, nil, nil)
+	}); err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to restore shoplist.")
+	}
+
+	return nil
+}
+
+// RestoreShoplistItem brings a deleted item back into its shoplist. The user must be a member of the shoplist.
+func (b *ShoplistBiz) RestoreShoplistItem(ctx context.Context, userID string, shoplistID int, itemID int) *ShoplistError {
+	role, isMember := b.getShoplistMemberRole(ctx, userID, shoplistID)
+	if !isMember {
+		return NewShoplistError(ShoplistNotFound, "Shoplist not found.")
+	}
+
+	// check if user can edit items
+	if !canEditItems(role) {
+		return NewShoplistError(ShoplistMemberReadOnly, "Viewers cannot modify items.")
+	}
+
+	var item db.ShoplistItem
+	err := b.dbPool.GetDB().WithContext(ctx).Unscoped().Where("id = ? AND shop_list_id = ? AND deleted_at IS NOT NULL", itemID, shoplistID).First(&item).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return NewShoplistError(ShoplistItemNotFound, "Item not found.")
+		}
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to check item.")
+	}
+
+	if err := b.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&db.ShoplistItem{}).Where("id = ?", itemID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return recordActivity(tx, shoplistID, userID, ActivityItemRestored, itemID, nil, itemActivityValues(item))
+	}); err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to restore item.")
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently deletes the shoplists, items and members that were deleted before the cutoff
+func (b *ShoplistBiz) PurgeTrash(ctx context.Context, cutoff time.Time) *ShoplistError {
+	if err := b.dbPool.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expiredShoplists := tx.Unscoped().Model(&db.Shoplist{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+
+		// Remove everything that belongs to the expired shoplists first, and the members that left before the cutoff
+		if err := tx.Unscoped().Where("shop_list_id IN (?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", expiredShoplists, cutoff).Delete(&db.ShoplistMember{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shop_list_id IN (?)", expiredShoplists).Delete(&db.ShoplistShareCode{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shop_list_id IN (?)", expiredShoplists).Delete(&db.ShoplistBan{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shop_list_id IN (?)", expiredShoplists).Delete(&db.ShoplistInvitation{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shop_list_id IN (?)", expiredShoplists).Delete(&db.ShoplistActivity{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shop_list_id IN (?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", expiredShoplists, cutoff).Delete(&db.ShoplistItem{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&db.Shoplist{}).Error
+	}); err != nil {
+		return NewShoplistError(ShoplistFailedToProcess, "Failed to purge trash.")
+	}
+
+	return nil
+}
+
+// StartTrashPurger periodically purges the trash until the context is cancelled.
+// Anything that has been in the trash for longer than the retention window is removed permanently.
+func (b *ShoplistBiz) StartTrashPurger(ctx context.Context, interval time.Duration) {
+	if b.trashRetention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := b.PurgeTrash(ctx, time.Now().Add(-b.trashRetention)); err != nil {
+					logger.Errorf("StartTrashPurger: Failed to purge trash. Error: %s", err.Error())
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,47 @@
+package bizshoplist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	dbmodel "netherealmstudio.com/m/v2/db"
+	testutil "netherealmstudio.com/m/v2/testUtil"
+)
+
+func TestPurgeTrash(t *testing.T) {
+	dbPool := testutil.SetupTestEnv(t)
+	setupSearchTestData(t, dbPool)
+	biz := InitializeShoplistBiz(*dbPool, NewInProcessShoplistEventBroker(0), 0)
+
+	longAgo := time.Now().Add(-48 * time.Hour)
+	recently := time.Now().Add(-1 * time.Hour)
+
+	// Shoplist 2 and its items were deleted long ago, item 1 long ago and item 3 recently
+	err := dbPool.GetDB().Model(&dbmodel.Shoplist{}).Where("id = ?", 2).Update("deleted_at", longAgo).Error
+	assert.NoError(t, err)
+	err = dbPool.GetDB().Model(&dbmodel.ShoplistItem{}).Where("shop_list_id = ?", 2).Update("deleted_at", longAgo).Error
+	assert.NoError(t, err)
+	err = dbPool.GetDB().Model(&dbmodel.ShoplistItem{}).Where("id = ?", 1).Update("deleted_at", longAgo).Error
+	assert.NoError(t, err)
+	err = dbPool.GetDB().Model(&dbmodel.ShoplistItem{}).Where("id = ?", 3).Update("deleted_at", recently).Error
+	assert.NoError(t, err)
+
+	shoplistErr := biz.PurgeTrash(context.Background(), time.Now().Add(-24*time.Hour))
+	assert.Nil(t, shoplistErr)
+
+	var count int64
+	dbPool.GetDB().Unscoped().Model(&dbmodel.Shoplist{}).Where("id = ?", 2).Count(&count)
+	assert.Equal(t, int64(0), count, "Expired shoplist should be purged")
+	dbPool.GetDB().Unscoped().Model(&dbmodel.ShoplistMember{}).Where("shop_list_id = ?", 2).Count(&count)
+	assert.Equal(t, int64(0), count, "Members of expired shoplist should be purged")
+	dbPool.GetDB().Unscoped().Model(&dbmodel.ShoplistItem{}).Where("shop_list_id = ?", 2).Count(&count)
+	assert.Equal(t, int64(0), count, "Items of expired shoplist should be purged")
+	dbPool.GetDB().Unscoped().Model(&dbmodel.ShoplistItem{}).Where("id = ?", 1).Count(&count)
+	assert.Equal(t, int64(0), count, "Expired item should be purged")
+	dbPool.GetDB().Unscoped().Model(&dbmodel.ShoplistItem{}).Where("id = ?", 3).Count(&count)
+	assert.Equal(t, int64(1), count, "Recently deleted item should be kept")
+	dbPool.GetDB().Unscoped().Model(&dbmodel.ShoplistItem{}).Where("id = ?", 2).Count(&count)
+	assert.Equal(t, int64(1), count, "Active item should be kept")
+}
@@ -0,0 +1,12 @@
+package bizshoplist
+
+// AnyVersion skips the version check of an update or delete, the last write wins
+const AnyVersion = 0
+
+// checkVersion returns a conflict when the caller changed a shoplist or item based on a version that is no longer current
+func checkVersion(expectedVersion int, currentVersion int) *ShoplistError {
+	if expectedVersion != AnyVersion && expectedVersion != currentVersion {
+		return NewShoplistError(ShoplistVersionConflict, "Version does not match the current version.")
+	}
+	return nil
+}
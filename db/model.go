@@ -19,6 +19,9 @@ type Shoplist struct {
 	OwnerID string `json:"-" gorm:"not null"`
 	Owner   User   `json:"owner" gorm:"foreignKey:OwnerID;reference:ID"`
 	Name    string `json:"name" gorm:"type:varchar(100);not null"`
+	Version int    `json:"version" gorm:"not null;default:1"`
+	// IsTemplate marks a saved template, which is left out of the shoplists of a user and instantiated into new shoplists
+	IsTemplate bool `json:"is_template" gorm:"type:tinyint(1);not null;default:0"`
 }
 
 type ShoplistShareCode struct {
@@ -28,6 +31,9 @@ type ShoplistShareCode struct {
 	ShopList   Shoplist  `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
 	Code       string    `json:"code" gorm:"type:varchar(6);not null"`
 	Expiry     time.Time `json:"expiry" gorm:"type:timestamp;"`
+	MaxUses    int       `json:"max_uses" gorm:"not null;default:0"`
+	UseCount   int       `json:"use_count" gorm:"not null;default:0"`
+	Role       string    `json:"role" gorm:"type:varchar(10);not null;default:'editor'"`
 }
 
 type ShoplistMember struct {
@@ -37,18 +43,87 @@ type ShoplistMember struct {
 	ShopList   Shoplist `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
 	MemberID   string   `json:"-" gorm:"not null;uniqueIndex:idx_shoplist_member"`
 	Member     User     `json:"member" gorm:"foreignKey:MemberID;reference:ID"`
+	Role       string   `json:"role" gorm:"type:varchar(10);not null;default:'editor'"`
+}
+
+type ShoplistBan struct {
+	gorm.Model
+	ID         int      `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
+	ShopListID int      `json:"-" gorm:"not null;uniqueIndex:idx_shoplist_ban"`
+	ShopList   Shoplist `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
+	MemberID   string   `json:"-" gorm:"type:varchar(32);not null;uniqueIndex:idx_shoplist_ban"`
+	BannedBy   string   `json:"banned_by" gorm:"type:varchar(32);not null"`
+}
+
+type ShoplistInvitation struct {
+	gorm.Model
+	ID         int      `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
+	ShopListID int      `json:"-" gorm:"not null;index:idx_shoplist_invitee"`
+	ShopList   Shoplist `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
+	InviteeID  string   `json:"-" gorm:"type:varchar(32);not null;index:idx_shoplist_invitee;index:idx_invitee_status"`
+	Invitee    User     `json:"invitee" gorm:"foreignKey:InviteeID;reference:ID"`
+	InvitedBy  string   `json:"invited_by" gorm:"type:varchar(32);not null"`
+	Role       string   `json:"role" gorm:"type:varchar(10);not null;default:'editor'"`
+	Status     string   `json:"status" gorm:"type:varchar(10);not null;default:'pending';index:idx_invitee_status"`
+}
+
+type ShoplistJoinAttempt struct {
+	gorm.Model
+	ID          int        `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
+	AttemptKey  string     `json:"attempt_key" gorm:"type:varchar(80);not null;uniqueIndex"`
+	FailedCount int        `json:"failed_count" gorm:"not null;default:0"`
+	WindowStart time.Time  `json:"window_start" gorm:"type:timestamp;"`
+	LockedUntil *time.Time `json:"locked_until" gorm:"type:timestamp NULL;"`
+}
+
+type ShoplistActivity struct {
+	gorm.Model
+	ID           int      `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
+	ShopListID   int      `json:"-" gorm:"not null;index"`
+	ShopList     Shoplist `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
+	ActorID      string   `json:"actor_id" gorm:"type:varchar(32);not null"`
+	Action       string   `json:"action" gorm:"type:varchar(40);not null"`
+	ItemID       *int     `json:"item_id" gorm:"type:int unsigned"`
+	BeforeValues string   `json:"before_values" gorm:"type:text"`
+	AfterValues  string   `json:"after_values" gorm:"type:text"`
 }
 
 type ShoplistItem struct {
 	gorm.Model
 	ID         int      `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
-	ShopListID int      `json:"-" gorm:"not null"`
+	ShopListID int      `json:"-" gorm:"not null;index:idx_shoplist_item_client_id"`
 	ShopList   Shoplist `json:"shoplist" gorm:"foreignKey:ShopListID;reference:ID"`
+	ClientID   string   `json:"-" gorm:"type:varchar(64);not null;default:'';index:idx_shoplist_item_client_id"`
 	ItemName   string   `json:"item_name" gorm:"type:varchar(100);not null"`
 	BrandName  string   `json:"brand_name" gorm:"type:varchar(100);not null"`
 	ExtraInfo  string   `json:"extra_info" gorm:"type:varchar(100);"`
+	Quantity   float64  `json:"quantity" gorm:"type:double;not null;default:0"`
+	Unit       string   `json:"unit" gorm:"type:varchar(10);not null;default:''"`
+	Category   string   `json:"category" gorm:"type:varchar(30);not null;default:'other'"`
 	IsBought   bool     `json:"is_bought" gorm:"type:tinyint(1);not null;default:0"`
+	Position   int      `json:"position" gorm:"not null;default:0"`
 	Thumbnail  string   `json:"thumbnail" gorm:"type:varchar(255);default:''"`
+	Version    int      `json:"version" gorm:"not null;default:1"`
+	// Recurrence is the rule that brings the item back after it is bought, empty when the item does not recur
+	Recurrence string `json:"recurrence" gorm:"type:varchar(20);not null;default:''"`
+	// RecurrenceDueAt is when a bought recurring item is marked as not bought again
+	RecurrenceDueAt *time.Time `json:"-" gorm:"type:timestamp NULL;index"`
+}
+
+// ShoplistPurchase records that an item was marked as bought. Purchases are kept after the item or the shoplist is
+// removed, so ShopListID and ItemID are not foreign keys.
+type ShoplistPurchase struct {
+	gorm.Model
+	ID          int       `json:"id" gorm:"type:int unsigned;primaryKey;autoIncrement:true;not null;AUTO_INCREMENT:10000"`
+	ShopListID  int       `json:"shoplist_id" gorm:"type:int unsigned;not null;index:idx_shoplist_purchase,priority:1"`
+	ItemID      int       `json:"item_id" gorm:"type:int unsigned;not null"`
+	ItemName    string    `json:"item_name" gorm:"type:varchar(100);not null"`
+	BrandName   string    `json:"brand_name" gorm:"type:varchar(100);not null"`
+	Quantity    float64   `json:"quantity" gorm:"type:double;not null;default:0"`
+	Unit        string    `json:"unit" gorm:"type:varchar(10);not null;default:''"`
+	Category    string    `json:"category" gorm:"type:varchar(30);not null;default:'other'"`
+	BuyerID     string    `json:"buyer_id" gorm:"type:varchar(32);not null;index:idx_buyer_purchase,priority:1"`
+	PurchasedAt time.Time `json:"purchased_at" gorm:"type:timestamp;not null;index:idx_shoplist_purchase,priority:2;index:idx_buyer_purchase,priority:2"`
 }
 
 type Flyer struct {
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/kdjuwidja/aishoppercommon/db"
 	"github.com/kdjuwidja/aishoppercommon/elasticsearch"
@@ -32,6 +34,11 @@ func main() {
 		&dbmodel.ShoplistItem{},
 		&dbmodel.ShoplistMember{},
 		&dbmodel.ShoplistShareCode{},
+		&dbmodel.ShoplistBan{},
+		&dbmodel.ShoplistInvitation{},
+		&dbmodel.ShoplistJoinAttempt{},
+		&dbmodel.ShoplistActivity{},
+		&dbmodel.ShoplistPurchase{},
 		&dbmodel.User{},
 	}
 	mysqlConn, err := db.InitializeMySQLConnectionPool(osutil.GetEnvString("AI_SHOPPER_CORE_DB_USER", "ai_shopper_dev"),
@@ -72,9 +79,12 @@ func main() {
 	methods := osutil.GetEnvString("CORS_ALLOW_METHODS", "GET, POST, PUT, DELETE, OPTIONS")
 	corsConfig.AllowMethods = strings.Split(methods, ",")
 
-	headers := osutil.GetEnvString("CORS_ALLOW_HEADERS", "Content-Type, Authorization")
+	headers := osutil.GetEnvString("CORS_ALLOW_HEADERS", "Content-Type, Authorization, If-Match")
 	corsConfig.AllowHeaders = strings.Split(headers, ",")
 
+	// Let browsers read the versions of shoplists and items for If-Match
+	corsConfig.ExposeHeaders = []string{"ETag"}
+
 	r.Use(cors.New(corsConfig))
 
 	// Initialize Response Factory
@@ -84,9 +94,24 @@ func main() {
 	tokenVerifier := apiHandlers.InitializeTokenVerifier(*rf)
 
 	// IntializeBiz
-	shoplistBiz := bizshoplist.InitializeShoplistBiz(*mysqlConn)
+	shoplistEventBroker := bizshoplist.NewInProcessShoplistEventBroker(osutil.GetEnvInt("AI_SHOPPER_CORE_SHOPLIST_EVENT_BUFFER", bizshoplist.DefaultShoplistEventBuffer))
+	trashRetention := time.Duration(osutil.GetEnvInt("AI_SHOPPER_CORE_TRASH_RETENTION_HOURS", 720)) * time.Hour
+	shoplistBiz := bizshoplist.InitializeShoplistBiz(*mysqlConn, shoplistEventBroker, trashRetention)
 	matchBiz := bizmatch.NewMatchShoplistItemsWithFlyerBiz(esc, mysqlConn)
 
+	// Start background jobs
+	bgCtx, cancelBg := context.WithCancel(context.Background())
+	defer cancelBg()
+
+	trashPurgeInterval := time.Duration(osutil.GetEnvInt("AI_SHOPPER_CORE_TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute
+	shoplistBiz.StartTrashPurger(bgCtx, trashPurgeInterval)
+
+	joinAttemptPurgeInterval := time.Duration(osutil.GetEnvInt("AI_SHOPPER_CORE_JOIN_ATTEMPT_PURGE_INTERVAL_MINUTES", 60)) * time.Minute
+	shoplistBiz.StartJoinAttemptPurger(bgCtx, joinAttemptPurgeInterval)
+
+	recurrenceInterval := time.Duration(osutil.GetEnvInt("AI_SHOPPER_CORE_RECURRENCE_INTERVAL_MINUTES", 5)) * time.Minute
+	shoplistBiz.StartRecurrenceScheduler(bgCtx, recurrenceInterval)
+
 	// Initialize API Handlers
 	healthHandler := apiHandlersHealth.InitializeHealthHandler()
 	userProfileHandler := apihandlersuser.InitializeUserProfileHandler(*mysqlConn, *rf)
@@ -101,18 +126,48 @@ func main() {
 	r.POST(getRoute(serviceName, "/v2/user"), tokenVerifier.VerifyToken([]string{"profile"}, userProfileHandler.CreateOrUpdateUserProfile))
 	r.PUT(getRoute(serviceName, "/v2/shoplist"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.CreateShoplist))
 	r.POST(getRoute(serviceName, "/v2/shoplist/:id"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.UpdateShoplist))
+	r.DELETE(getRoute(serviceName, "/v2/shoplist/:id"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.DeleteShoplist))
 	r.POST(getRoute(serviceName, "/v2/shoplist/:id/leave"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.LeaveShopList))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/transfer-ownership"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.TransferShoplistOwnership))
 	r.POST(getRoute(serviceName, "/v2/shoplist/:id/share-code"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RequestShopListShareCode))
 	r.POST(getRoute(serviceName, "/v2/shoplist/:id/share-code/revoke"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RevokeShopListShareCode))
 	r.POST(getRoute(serviceName, "/v2/shoplist/join"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.JoinShopList))
 	r.PUT(getRoute(serviceName, "/v2/shoplist/:id/item"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.AddItemToShopList))
 	r.DELETE(getRoute(serviceName, "/v2/shoplist/:id/item/:itemId"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RemoveItemFromShopList))
 	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/:itemId"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.UpdateShoplistItem))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/reorder"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.ReorderShoplistItems))
+	r.PUT(getRoute(serviceName, "/v2/shoplist/:id/item/bulk"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.AddItemsToShopList))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/bulk"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.UpdateShoplistItems))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/bulk/delete"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RemoveItemsFromShopList))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/mark-all-bought"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.MarkAllItemsBought))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/unmark-all-bought"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.UnmarkAllItemsBought))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/clear-bought"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.ClearBoughtItems))
 	r.GET(getRoute(serviceName, "/v2/search/flyers"), tokenVerifier.VerifyToken([]string{"search"}, searchHandler.SearchFlyers))
 	r.GET(getRoute(serviceName, "/v2/match/flyers"), tokenVerifier.VerifyToken([]string{"search"}, matchHandler.MatchShoplistItemsWithFlyer))
 	r.GET(getRoute(serviceName, "/v2/shoplist"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetAllShoplistAndItemsForUser))
 	r.GET(getRoute(serviceName, "/v2/shoplist/:id"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistAndItemsForUserByShoplistID))
 	r.GET(getRoute(serviceName, "/v2/shoplist/:id/members"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistMembers))
+	r.GET(getRoute(serviceName, "/v2/shoplist/:id/activity"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistActivity))
+	r.GET(getRoute(serviceName, "/v2/shoplist/:id/events"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.StreamShoplistEvents))
+	r.DELETE(getRoute(serviceName, "/v2/shoplist/:id/members/:memberId"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RemoveShoplistMember))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/members/:memberId/role"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.UpdateShoplistMemberRole))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/invitations"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.InviteToShoplist))
+	r.GET(getRoute(serviceName, "/v2/shoplist/invitations"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistInvitations))
+	r.POST(getRoute(serviceName, "/v2/shoplist/invitations/:invitationId/accept"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.AcceptShoplistInvitation))
+	r.POST(getRoute(serviceName, "/v2/shoplist/invitations/:invitationId/decline"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.DeclineShoplistInvitation))
+	r.GET(getRoute(serviceName, "/v2/shoplist/trash"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetTrash))
+	r.GET(getRoute(serviceName, "/v2/shoplist/sync"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.SyncShoplists))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/replay"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.ReplayOfflineOps))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/restore"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RestoreShoplist))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/item/:itemId/restore"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.RestoreShoplistItem))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/clone"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.CloneShoplist))
+	r.POST(getRoute(serviceName, "/v2/shoplist/:id/template"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.SaveShoplistAsTemplate))
+	r.GET(getRoute(serviceName, "/v2/shoplist/template"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistTemplates))
+	r.POST(getRoute(serviceName, "/v2/shoplist/template/:id/instantiate"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.InstantiateShoplistTemplate))
+	r.GET(getRoute(serviceName, "/v2/shoplist/purchases"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetUserPurchaseHistory))
+	r.GET(getRoute(serviceName, "/v2/shoplist/:id/purchases"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetShoplistPurchaseHistory))
+	r.GET(getRoute(serviceName, "/v2/shoplist/:id/suggestions"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.GetItemSuggestions))
+	r.GET(getRoute(serviceName, "/v2/shoplist/item/parse"), tokenVerifier.VerifyToken([]string{"shoplist"}, shoplistHandler.ParseItemText))
 
 	logger.Info("Starting server on port 8080")
 	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
@@ -37,6 +37,11 @@ func SetupTestEnv(t *testing.T) *db.MySQLConnectionPool {
 		&dbmodel.ShoplistItem{},
 		&dbmodel.ShoplistMember{},
 		&dbmodel.ShoplistShareCode{},
+		&dbmodel.ShoplistBan{},
+		&dbmodel.ShoplistInvitation{},
+		&dbmodel.ShoplistJoinAttempt{},
+		&dbmodel.ShoplistActivity{},
+		&dbmodel.ShoplistPurchase{},
 		&dbmodel.User{},
 	}
 	testDBConn := SetupTestDB(t, models)